@@ -1,32 +1,101 @@
 package cinemate
 
 import (
-	"encoding/xml"
+	"context"
+	"errors"
 	"net/url"
 	"strconv"
-	"time"
 )
 
+// passkeyLength число 16-ричных цифр PASSKEY
+const passkeyLength = 40
+
+// Login Авторизация по логину и паролю. Логин и пароль передаются в теле
+// POST запроса к account.auth, а не в адресе запроса.
+// username логин пользователя
+// password пароль пользователя
+// Возвращает Account с полученным PASSKEY. Неверный логин или пароль
+// возвращают *AuthError, соответствующую ErrAuthFailed. Ошибки лимита
+// запросов, сервера и сети возвращаются без AuthError
+func Login(ctx context.Context, username string, password string) (*Account, error) {
+	return defaultClient.Login(ctx, username, password)
+}
+
+// Login Авторизация по логину и паролю на адресе WithAuthURL или базовом
+// адресе клиента. Возвращает Account, использующий Client
+func (c *Client) Login(ctx context.Context, username string, password string) (*Account, error) {
+	var result Account
+	form := url.Values{}
+	form.Set("username", username)
+	form.Set("password", password)
+	authURL := c.authURL
+	if authURL == "" {
+		authURL = c.baseURL
+	}
+	err := c.post(ctx, authURL, "/account.auth", url.Values{}, form, &result)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && credentialsRejected(apiErr) {
+			err = &AuthError{Username: username, Err: err}
+		}
+		return nil, err
+	}
+	if !ValidPasskey(result.Passkey) {
+		return nil, &AuthError{Username: username, Err: errMalformedPasskey}
+	}
+	return &Account{Passkey: result.Passkey, client: c}, nil
+}
+
+// ValidPasskey report whether passkey is 40 hex digits
+func ValidPasskey(passkey string) bool {
+	if len(passkey) != passkeyLength {
+		return false
+	}
+	for _, r := range passkey {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'f', r >= 'A' && r <= 'F':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // GetAccountAuth Авторизация по логину и паролю.
 // Пример запроса: http://api.cinemate.cc/account.auth?username=USERNAME&password=PASSWORD
 // username логин пользователя
 // password пароль пользователя
+//
+// Deprecated: логин и пароль передаются в адресе запроса, используйте Login
 func GetAccountAuth(username string, password string) (passkey string, err error) {
-	time.Sleep(1 * time.Second)
-	var u url.URL
+	return defaultClient.GetAccountAuthContext(context.Background(), username, password)
+}
+
+// GetAccountAuthContext is GetAccountAuth with context
+//
+// Deprecated: используйте Login
+func GetAccountAuthContext(ctx context.Context, username string, password string) (passkey string, err error) {
+	return defaultClient.GetAccountAuthContext(ctx, username, password)
+}
+
+// GetAccountAuth Авторизация по логину и паролю.
+// username логин пользователя
+// password пароль пользователя
+//
+// Deprecated: логин и пароль передаются в адресе запроса, используйте Login
+func (c *Client) GetAccountAuth(username string, password string) (passkey string, err error) {
+	return c.GetAccountAuthContext(context.Background(), username, password)
+}
+
+// GetAccountAuthContext is GetAccountAuth with context
+//
+// Deprecated: используйте Login
+func (c *Client) GetAccountAuthContext(ctx context.Context, username string, password string) (passkey string, err error) {
 	var result Account
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/account.auth"
-	q := u.Query()
+	q := url.Values{}
 	q.Set("username", username)
 	q.Set("password", password)
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
-	if err != nil {
-		return
-	}
-	err = xml.Unmarshal(xmlBody, &result)
+	err = c.get(ctx, "/account.auth", q, &result)
 	if err == nil {
 		passkey = result.Passkey
 	}
@@ -38,20 +107,32 @@ func GetAccountAuth(username string, password string) (passkey string, err error
 // PASSKEY уникальное для каждого пользователя 40-значное 16-ричное число, получить которое можно на странице настроек
 // format  необязательный параметр формата возвращаемых сервером данных: xml (по умолчанию) или json
 func (acc *Account) GetAccountProfile() (profile AccountProfile, err error) {
-	time.Sleep(1 * time.Second)
-	var u url.URL
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/account.profile"
-	q := u.Query()
-	q.Set("passkey", acc.Passkey)
-	q.Set("format", "xml")
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
-	if err != nil {
+	return acc.GetAccountProfileContext(context.Background())
+}
+
+// GetAccountProfileContext is GetAccountProfile with context
+func (acc *Account) GetAccountProfileContext(ctx context.Context) (profile AccountProfile, err error) {
+	err = acc.withPasskey(ctx, func(passkey string) (err error) {
+		profile, err = acc.getClient().accountProfile(ctx, passkey)
 		return
-	}
-	err = xml.Unmarshal(xmlBody, &profile)
+	})
+	return
+}
+
+// GetAccountProfile Данные и статистика пользовательского аккаунта с passkey клиента
+func (c *Client) GetAccountProfile() (profile AccountProfile, err error) {
+	return c.accountProfile(context.Background(), c.passkey)
+}
+
+// GetAccountProfileContext is GetAccountProfile with context
+func (c *Client) GetAccountProfileContext(ctx context.Context) (profile AccountProfile, err error) {
+	return c.accountProfile(ctx, c.passkey)
+}
+
+func (c *Client) accountProfile(ctx context.Context, passkey string) (profile AccountProfile, err error) {
+	q := url.Values{}
+	q.Set("passkey", passkey)
+	err = c.get(ctx, "/account.profile", q, &profile)
 	return
 }
 
@@ -61,27 +142,39 @@ func (acc *Account) GetAccountProfile() (profile AccountProfile, err error) {
 // newonly если 1, то возвращается список только непрочитанных записей в ленте
 // format  необязательный параметр формата возвращаемых сервером данных: xml (по умолчанию) или json
 func (acc *Account) GetAccountUpdateList(newonly ...bool) (list UpdateList, err error) {
-	time.Sleep(1 * time.Second)
-	var u url.URL
+	return acc.GetAccountUpdateListContext(context.Background(), newonly...)
+}
+
+// GetAccountUpdateListContext is GetAccountUpdateList with context
+func (acc *Account) GetAccountUpdateListContext(ctx context.Context, newonly ...bool) (list UpdateList, err error) {
+	err = acc.withPasskey(ctx, func(passkey string) (err error) {
+		list, err = acc.getClient().accountUpdateList(ctx, passkey, newonly...)
+		return
+	})
+	return
+}
+
+// GetAccountUpdateList Метод возвращает записи ленты обновлений пользователя с passkey клиента
+func (c *Client) GetAccountUpdateList(newonly ...bool) (list UpdateList, err error) {
+	return c.accountUpdateList(context.Background(), c.passkey, newonly...)
+}
+
+// GetAccountUpdateListContext is GetAccountUpdateList with context
+func (c *Client) GetAccountUpdateListContext(ctx context.Context, newonly ...bool) (list UpdateList, err error) {
+	return c.accountUpdateList(ctx, c.passkey, newonly...)
+}
+
+func (c *Client) accountUpdateList(ctx context.Context, passkey string, newonly ...bool) (list UpdateList, err error) {
 	newOnlyInt := 1
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/account.updatelist"
-	q := u.Query()
-	q.Set("passkey", acc.Passkey)
+	q := url.Values{}
+	q.Set("passkey", passkey)
 	if len(newonly) > 0 {
 		if newonly[0] == false {
 			newOnlyInt = 0
 		}
 	}
 	q.Set("newonly", strconv.FormatInt(int64(newOnlyInt), 10))
-	q.Set("format", "xml")
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
-	if err != nil {
-		return
-	}
-	err = xml.Unmarshal(xmlBody, &list)
+	err = c.get(ctx, "/account.updatelist", q, &list)
 	return
 }
 
@@ -90,19 +183,31 @@ func (acc *Account) GetAccountUpdateList(newonly ...bool) (list UpdateList, err
 // PASSKEY уникальное для каждого пользователя 40-значное 16-ричное число, получить которое можно на странице настроек
 // format  необязательный параметр формата возвращаемых сервером данных: xml (по умолчанию) или json
 func (acc *Account) GetAccountWatchlist() (list WatchList, err error) {
-	time.Sleep(1 * time.Second)
-	var u url.URL
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/account.watchlist"
-	q := u.Query()
-	q.Set("passkey", acc.Passkey)
-	q.Set("format", "xml")
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
-	if err != nil {
+	return acc.GetAccountWatchlistContext(context.Background())
+}
+
+// GetAccountWatchlistContext is GetAccountWatchlist with context
+func (acc *Account) GetAccountWatchlistContext(ctx context.Context) (list WatchList, err error) {
+	err = acc.withPasskey(ctx, func(passkey string) (err error) {
+		list, err = acc.getClient().accountWatchlist(ctx, passkey)
 		return
-	}
-	err = xml.Unmarshal(xmlBody, &list)
+	})
+	return
+}
+
+// GetAccountWatchlist Метод возвращает список объектов слежения пользователя с passkey клиента
+func (c *Client) GetAccountWatchlist() (list WatchList, err error) {
+	return c.accountWatchlist(context.Background(), c.passkey)
+}
+
+// GetAccountWatchlistContext is GetAccountWatchlist with context
+func (c *Client) GetAccountWatchlistContext(ctx context.Context) (list WatchList, err error) {
+	return c.accountWatchlist(ctx, c.passkey)
+}
+
+func (c *Client) accountWatchlist(ctx context.Context, passkey string) (list WatchList, err error) {
+	q := url.Values{}
+	q.Set("passkey", passkey)
+	err = c.get(ctx, "/account.watchlist", q, &list)
 	return
 }
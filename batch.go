@@ -0,0 +1,82 @@
+package cinemate
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBatchWorkers число одновременных запросов GetMovies и GetPersons
+const defaultBatchWorkers = 4
+
+// BatchResult result of request of one object of batch
+// ID    ID объекта
+// Value объект
+// Err   ошибка запроса объекта или nil
+type BatchResult[T any] struct {
+	ID    int64
+	Value T
+	Err   error
+}
+
+// WithBatchWorkers set number of concurrent requests of GetMovies and
+// GetPersons, default 4
+func WithBatchWorkers(workers int) Option {
+	return func(c *Client) {
+		c.batchWorkers = workers
+	}
+}
+
+// GetMovies Информация о нескольких фильмах. Запросы выполняются
+// параллельно с ограничением числа запросов клиента, повторяющиеся ID
+// запрашиваются один раз. Результаты возвращаются в порядке ids
+func (c *Client) GetMovies(ctx context.Context, ids []int64) []BatchResult[Movie] {
+	return batch(ctx, c.batchWorkers, ids, c.GetMovieContext)
+}
+
+// GetPersons Информация о нескольких персонах. Запросы выполняются
+// параллельно с ограничением числа запросов клиента, повторяющиеся ID
+// запрашиваются один раз. Результаты возвращаются в порядке ids
+func (c *Client) GetPersons(ctx context.Context, ids []int64) []BatchResult[Person] {
+	return batch(ctx, c.batchWorkers, ids, c.GetPersonContext)
+}
+
+// batch fetch unique ids by pool of workers and return results in order of ids
+func batch[T any](ctx context.Context, workers int, ids []int64, fetch func(context.Context, int64) (T, error)) []BatchResult[T] {
+	if workers < 1 {
+		workers = 1
+	}
+	unique := make(map[int64]*BatchResult[T], len(ids))
+	jobs := make(chan *BatchResult[T], len(ids))
+	for _, id := range ids {
+		if _, ok := unique[id]; ok {
+			continue
+		}
+		r := &BatchResult[T]{ID: id}
+		unique[id] = r
+		jobs <- r
+	}
+	close(jobs)
+	if workers > len(unique) {
+		workers = len(unique)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				if err := ctx.Err(); err != nil {
+					r.Err = err
+					continue
+				}
+				r.Value, r.Err = fetch(ctx, r.ID)
+			}
+		}()
+	}
+	wg.Wait()
+	results := make([]BatchResult[T], len(ids))
+	for i, id := range ids {
+		results[i] = *unique[id]
+	}
+	return results
+}
@@ -0,0 +1,97 @@
+package cinemate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchOrderAndDedup(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls = map[int64]int{}
+	)
+	fetch := func(ctx context.Context, id int64) (string, error) {
+		mu.Lock()
+		calls[id]++
+		mu.Unlock()
+		time.Sleep(time.Duration(10-id) * time.Millisecond)
+		if id == 3 {
+			return "", ErrNotFound
+		}
+		return fmt.Sprint("v", id), nil
+	}
+	ids := []int64{5, 1, 3, 5, 2, 1}
+	results := batch(context.Background(), 3, ids, fetch)
+	if len(results) != len(ids) {
+		t.Fatalf("got %d results, want %d", len(results), len(ids))
+	}
+	for i, r := range results {
+		if r.ID != ids[i] {
+			t.Errorf("results[%d].ID = %d, want %d", i, r.ID, ids[i])
+		}
+		if r.ID == 3 {
+			if !errors.Is(r.Err, ErrNotFound) {
+				t.Errorf("results[%d].Err = %v, want ErrNotFound", i, r.Err)
+			}
+		} else if r.Err != nil || r.Value != fmt.Sprint("v", r.ID) {
+			t.Errorf("results[%d] = %+v", i, r)
+		}
+	}
+	for id, n := range calls {
+		if n != 1 {
+			t.Errorf("id %d fetched %d times, want 1", id, n)
+		}
+	}
+	if len(calls) != 4 {
+		t.Errorf("fetched %d ids, want 4 unique", len(calls))
+	}
+}
+
+func TestBatchWorkers(t *testing.T) {
+	var running, peak int32
+	fetch := func(ctx context.Context, id int64) (int64, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return id, nil
+	}
+	batch(context.Background(), 2, []int64{1, 2, 3, 4, 5, 6}, fetch)
+	if peak > 2 {
+		t.Errorf("peak of concurrent fetches = %d, want at most 2", peak)
+	}
+	if results := batch(context.Background(), 0, []int64{1}, fetch); results[0].Value != 1 {
+		t.Errorf("batch with zero workers = %+v", results)
+	}
+	if results := batch(context.Background(), 4, nil, fetch); len(results) != 0 {
+		t.Errorf("batch of no ids = %+v", results)
+	}
+}
+
+func TestBatchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int32
+	results := batch(ctx, 2, []int64{1, 2}, func(ctx context.Context, id int64) (int64, error) {
+		atomic.AddInt32(&calls, 1)
+		return id, nil
+	})
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("result %d err = %v, want Canceled", r.ID, r.Err)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("fetched %d ids after cancel, want 0", calls)
+	}
+}
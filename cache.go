@@ -0,0 +1,242 @@
+package cinemate
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache store raw response bodies of api server. Cache must be safe for
+// concurrent use
+type Cache interface {
+	// Get return value of key and true if value exists and not expired
+	Get(key string) ([]byte, bool)
+	// Set store value of key for ttl
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// CacheStats statistics of cache use by Client
+// Hits   число ответов из кэша
+// Misses число запросов к серверу при отсутствии ответа в кэше
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// defaultCacheTTL time to live of cached responses by api method path,
+// methods not listed here are not cached
+var defaultCacheTTL = map[string]time.Duration{
+	"/movie":              24 * time.Hour,
+	"/movie.list":         time.Hour,
+	"/movie.search":       time.Hour,
+	"/person":             24 * time.Hour,
+	"/person.movies":      24 * time.Hour,
+	"/person.search":      time.Hour,
+	"/account.profile":    5 * time.Minute,
+	"/account.updatelist": time.Minute,
+	"/account.watchlist":  5 * time.Minute,
+	"/stats.new":          10 * time.Minute,
+}
+
+// secretParams query parameters excluded from cache keys
+var secretParams = []string{"apikey", "passkey", "password"}
+
+type cacheState struct {
+	cache   Cache
+	offline bool
+	ttl     map[string]time.Duration
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+// WithCache set Cache for responses of api server
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache.cache = cache
+	}
+}
+
+// WithCacheTTL set time to live of cached responses of api method path, for
+// example "/movie". Zero ttl disable cache of method. Responses of
+// /account.auth are never cached
+func WithCacheTTL(path string, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cache.ttl[path] = ttl
+	}
+}
+
+// WithOffline set offline mode: responses are served only from cache, even
+// expired if cache is StaleCache, and ErrCacheMiss is returned for responses
+// absent in cache
+func WithOffline(offline bool) Option {
+	return func(c *Client) {
+		c.cache.offline = offline
+	}
+}
+
+// CacheStats return statistics of cache use
+func (c *Client) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:   c.cache.hits.Load(),
+		Misses: c.cache.misses.Load(),
+	}
+}
+
+func newCacheState() *cacheState {
+	ttl := make(map[string]time.Duration, len(defaultCacheTTL))
+	for path, d := range defaultCacheTTL {
+		ttl[path] = d
+	}
+	return &cacheState{ttl: ttl}
+}
+
+// cachedBody return response body of api method from cache or from server
+func (c *Client) cachedBody(ctx context.Context, call *callInfo, path string, q url.Values) ([]byte, error) {
+	if c.cache.offline {
+		return c.offlineBody(call, path, q)
+	}
+	ttl := c.cache.ttl[path]
+	if c.cache.cache == nil || ttl <= 0 || path == "/account.auth" {
+		return c.getBody(ctx, call, path, q)
+	}
+	key := cacheKey(path, q)
+	if body, ok := c.cache.cache.Get(key); ok {
+		c.cache.hits.Add(1)
+		call.cache = CacheHit
+		call.bytes = len(body)
+		return body, nil
+	}
+	c.cache.misses.Add(1)
+	call.cache = CacheMiss
+	body, err := c.getBody(ctx, call, path, q)
+	if err != nil {
+		return body, err
+	}
+	c.cache.cache.Set(key, body, ttl)
+	return body, nil
+}
+
+// offlineBody return response body of api method only from cache
+func (c *Client) offlineBody(call *callInfo, path string, q url.Values) ([]byte, error) {
+	call.cache = CacheMiss
+	if c.cache.cache == nil || path == "/account.auth" {
+		c.cache.misses.Add(1)
+		return nil, ErrCacheMiss
+	}
+	key := cacheKey(path, q)
+	var (
+		body []byte
+		ok   bool
+	)
+	if stale, isStale := c.cache.cache.(StaleCache); isStale {
+		body, _, ok = stale.GetStale(key)
+	} else {
+		body, ok = c.cache.cache.Get(key)
+	}
+	if !ok {
+		c.cache.misses.Add(1)
+		return nil, ErrCacheMiss
+	}
+	c.cache.hits.Add(1)
+	call.cache = CacheHit
+	call.bytes = len(body)
+	return body, nil
+}
+
+// cacheKey return key of api method path with normalized query. Secrets are
+// excluded from key, passkey is replaced by its hash so accounts not share
+// cached responses
+func cacheKey(path string, q url.Values) string {
+	nq := make(url.Values, len(q))
+	for k, v := range q {
+		nq[k] = v
+	}
+	for _, k := range secretParams {
+		nq.Del(k)
+	}
+	key := path + "?" + nq.Encode()
+	if passkey := q.Get("passkey"); passkey != "" {
+		sum := sha256.Sum256([]byte(passkey))
+		key += "#" + hex.EncodeToString(sum[:8])
+	}
+	return key
+}
+
+// MemoryCache in-memory LRU Cache with limited number of entries
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache create MemoryCache with maximum size entries
+func NewMemoryCache(size int) *MemoryCache {
+	if size < 1 {
+		size = 1
+	}
+	return &MemoryCache{
+		size:    size,
+		ll:      list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get return value of key and true if value exists and not expired
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		m.remove(el)
+		return nil, false
+	}
+	m.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+// Set store value of key for ttl, least recently used entry is removed if
+// cache is full
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expires := time.Now().Add(ttl)
+	if el, ok := m.entries[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value = value
+		entry.expires = expires
+		m.ll.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.ll.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for m.ll.Len() > m.size {
+		m.remove(m.ll.Back())
+	}
+}
+
+// Len return number of entries in cache
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+func (m *MemoryCache) remove(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.entries, el.Value.(*memoryEntry).key)
+}
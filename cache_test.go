@@ -0,0 +1,100 @@
+package cinemate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheLRU(t *testing.T) {
+	m := NewMemoryCache(2)
+	m.Set("a", []byte("1"), time.Hour)
+	m.Set("b", []byte("2"), time.Hour)
+	if _, ok := m.Get("a"); !ok {
+		t.Fatal("a evicted early")
+	}
+	m.Set("c", []byte("3"), time.Hour)
+	if _, ok := m.Get("b"); ok {
+		t.Error("least recently used b not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := m.Get(key); !ok {
+			t.Errorf("%s evicted, want kept", key)
+		}
+	}
+	m.Set("a", []byte("4"), time.Hour)
+	if v, _ := m.Get("a"); string(v) != "4" || m.Len() != 2 {
+		t.Errorf("a = %q, len %d, want updated value and 2 entries", v, m.Len())
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	m := NewMemoryCache(10)
+	m.Set("a", []byte("1"), -time.Second)
+	if _, ok := m.Get("a"); ok {
+		t.Error("expired entry returned")
+	}
+	if m.Len() != 0 {
+		t.Errorf("Len() = %d, want expired entry removed", m.Len())
+	}
+	m.Set("b", []byte("2"), time.Hour)
+	if v, ok := m.Get("b"); !ok || string(v) != "2" {
+		t.Errorf("Get(b) = %q, %v", v, ok)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	q1 := url.Values{"apikey": {"KEY1"}, "id": {"1"}, "format": {"xml"}}
+	q2 := url.Values{"format": {"xml"}, "id": {"1"}, "apikey": {"KEY2"}}
+	if cacheKey("/movie", q1) != cacheKey("/movie", q2) {
+		t.Error("keys differ by apikey or order of parameters")
+	}
+	if key := cacheKey("/movie", q1); strings.Contains(key, "KEY1") {
+		t.Errorf("key %q contains apikey", key)
+	}
+	p1 := url.Values{"passkey": {"PASSKEY1"}}
+	p2 := url.Values{"passkey": {"PASSKEY2"}}
+	if k1, k2 := cacheKey("/account.profile", p1), cacheKey("/account.profile", p2); k1 == k2 || strings.Contains(k1, "PASSKEY1") {
+		t.Errorf("keys %q, %q: want distinct keys without passkey", k1, k2)
+	}
+}
+
+// countingServer return server answering with body and counter of requests
+func countingServer(t *testing.T, body string) (*httptest.Server, *int32) {
+	t.Helper()
+	var n int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&n, 1)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &n
+}
+
+func TestClientCache(t *testing.T) {
+	srv, n := countingServer(t, `<response><movie><id>2</id></movie></response>`)
+	c := NewClient(WithBaseURL(srv.URL), WithRateLimiter(nil), WithCache(NewMemoryCache(10)), WithCacheTTL("/movie.search", 0))
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetMovie(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *n != 1 {
+		t.Errorf("GetMovie sent %d requests, want 1", *n)
+	}
+	if s := c.CacheStats(); s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("CacheStats() = %+v, want 1 hit and 1 miss", s)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetMovieSearch("чтиво"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *n != 3 {
+		t.Errorf("search with zero ttl sent %d requests, want 2", *n-1)
+	}
+}
@@ -0,0 +1,174 @@
+package cinemate
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MovieType тип фильмов
+type MovieType string
+
+// State состояние фильма
+type State string
+
+// Mode специальный режим отображения фильмов
+type Mode string
+
+// OrderBy критерий сортировки
+type OrderBy string
+
+// Order порядок сортировки
+type Order string
+
+// Values of CCRequest parameters
+const (
+	MovieTypeMovie  MovieType = "movie"
+	MovieTypeSerial MovieType = "serial"
+	MovieTypeShort  MovieType = "short"
+
+	StateSoon   State = "soon"
+	StateCinema State = "cinema"
+
+	ModeBest Mode = "best"
+
+	OrderByCreateDate    OrderBy = "create_date"
+	OrderByReleaseDate   OrderBy = "release_date"
+	OrderByRuReleaseDate OrderBy = "ru_release_date"
+
+	OrderDesc Order = "desc"
+	OrderAsc  Order = "asc"
+)
+
+// MaxPerPage максимальное число записей на странице movie.list
+const MaxPerPage = 25
+
+// ccDateLayout формат даты ДД.ММ.ГГГГ параметров from и to
+const ccDateLayout = "02.01.2006"
+
+// Validate check all parameters of CCRequest and return error with all
+// problems found or nil
+func (ccr CCRequest) Validate() error {
+	var errs []error
+	switch ccr.Type {
+	case "", MovieTypeMovie, MovieTypeSerial, MovieTypeShort:
+	default:
+		errs = append(errs, fmt.Errorf("invalid type %q", ccr.Type))
+	}
+	switch ccr.State {
+	case "", StateSoon, StateCinema:
+	default:
+		errs = append(errs, fmt.Errorf("invalid state %q", ccr.State))
+	}
+	switch ccr.Mode {
+	case "", ModeBest:
+	default:
+		errs = append(errs, fmt.Errorf("invalid mode %q", ccr.Mode))
+	}
+	switch ccr.OrderBy {
+	case "", OrderByCreateDate, OrderByReleaseDate, OrderByRuReleaseDate:
+	default:
+		errs = append(errs, fmt.Errorf("invalid order_by %q", ccr.OrderBy))
+	}
+	switch ccr.Order {
+	case "", OrderDesc, OrderAsc:
+	default:
+		errs = append(errs, fmt.Errorf("invalid order %q", ccr.Order))
+	}
+	switch ccr.Format {
+	case "", FormatXML, FormatJSON:
+	default:
+		errs = append(errs, fmt.Errorf("invalid format %q", ccr.Format))
+	}
+	if ccr.Year < 0 {
+		errs = append(errs, fmt.Errorf("invalid year %d", ccr.Year))
+	}
+	if ccr.Page < 0 {
+		errs = append(errs, fmt.Errorf("invalid page %d", ccr.Page))
+	}
+	if ccr.PerPage < 0 || ccr.PerPage > MaxPerPage {
+		errs = append(errs, fmt.Errorf("invalid per_page %d, must be from 1 to %d or 0 for default", ccr.PerPage, MaxPerPage))
+	}
+	if !ccr.From.IsZero() && !ccr.To.IsZero() && ccr.From.After(ccr.To) {
+		errs = append(errs, fmt.Errorf("from %s is after to %s", ccDate(ccr.From), ccDate(ccr.To)))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+// Query validate CCRequest and return query parameters of movie.list
+func (ccr CCRequest) Query() (url.Values, error) {
+	if err := ccr.Validate(); err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	if ccr.Type != "" {
+		q.Set("type", string(ccr.Type))
+	}
+	if ccr.State != "" {
+		q.Set("state", string(ccr.State))
+	}
+	if ccr.Mode != "" {
+		q.Set("mode", string(ccr.Mode))
+	}
+	if ccr.Year != 0 {
+		q.Set("year", strconv.FormatInt(ccr.Year, 10))
+	}
+	if ccr.Genre != "" {
+		q.Set("genre", ccr.Genre)
+	}
+	if ccr.Country != "" {
+		q.Set("country", ccr.Country)
+	}
+	if ccr.OrderBy != "" {
+		q.Set("order_by", string(ccr.OrderBy))
+	}
+	if ccr.Order != "" {
+		q.Set("order", string(ccr.Order))
+	}
+	if !ccr.From.IsZero() {
+		q.Set("from", ccDate(ccr.From))
+	}
+	if !ccr.To.IsZero() {
+		q.Set("to", ccDate(ccr.To))
+	}
+	if ccr.Page != 0 {
+		q.Set("page", strconv.FormatInt(ccr.Page, 10))
+	}
+	if ccr.PerPage != 0 {
+		q.Set("per_page", strconv.FormatInt(ccr.PerPage, 10))
+	}
+	if ccr.Format != "" {
+		q.Set("format", string(ccr.Format))
+	}
+	return q, nil
+}
+
+// ccDate format bound of order_by by calendar of site in Moscow timezone
+func ccDate(t time.Time) string {
+	return t.In(Moscow).Format(ccDateLayout)
+}
+
+// ValidationError is list of problems of CCRequest
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	msg := "cinemate: invalid request: "
+	for i, err := range e.Errors {
+		if i > 0 {
+			msg += "; "
+		}
+		msg += err.Error()
+	}
+	return msg
+}
+
+// Unwrap return all problems of CCRequest
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
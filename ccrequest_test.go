@@ -0,0 +1,69 @@
+package cinemate
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCCRequestQueryDates(t *testing.T) {
+	tests := []struct {
+		name string
+		from time.Time
+		want string
+	}{
+		{"utc evening is next day in Moscow", time.Date(2010, 5, 31, 22, 0, 0, 0, time.UTC), "01.06.2010"},
+		{"utc morning", time.Date(2010, 5, 31, 8, 0, 0, 0, time.UTC), "31.05.2010"},
+		{"moscow midnight", time.Date(2010, 6, 1, 0, 0, 0, 0, Moscow), "01.06.2010"},
+		{"los angeles evening", time.Date(2010, 5, 31, 18, 0, 0, 0, time.FixedZone("PDT", -7*60*60)), "01.06.2010"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := CCRequest{From: tt.from, To: tt.from}.Query()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := q.Get("from"); got != tt.want {
+				t.Errorf("from = %q, want %q", got, tt.want)
+			}
+			if got := q.Get("to"); got != tt.want {
+				t.Errorf("to = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCCRequestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		ccr  CCRequest
+		errs int
+	}{
+		{"empty", CCRequest{}, 0},
+		{"valid", CCRequest{Type: MovieTypeSerial, Year: 2010, PerPage: MaxPerPage, Order: OrderAsc}, 0},
+		{"bad enums", CCRequest{Type: "cartoon", Order: "up"}, 2},
+		{"per_page", CCRequest{PerPage: MaxPerPage + 1}, 1},
+		{"negative per_page", CCRequest{PerPage: -1}, 1},
+		{"from after to", CCRequest{From: time.Date(2011, 1, 1, 0, 0, 0, 0, Moscow), To: time.Date(2010, 1, 1, 0, 0, 0, 0, Moscow)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ccr.Validate()
+			var verr *ValidationError
+			switch {
+			case tt.errs == 0 && err != nil:
+				t.Errorf("Validate() = %v, want nil", err)
+			case tt.errs > 0 && (!errors.As(err, &verr) || len(verr.Errors) != tt.errs):
+				t.Errorf("Validate() = %v, want %d errors", err, tt.errs)
+			}
+		})
+	}
+}
+
+func TestCCRequestValidatePerPageMessage(t *testing.T) {
+	err := CCRequest{PerPage: MaxPerPage + 1}.Validate()
+	want := "cinemate: invalid request: invalid per_page 26, must be from 1 to 25 or 0 for default"
+	if err == nil || err.Error() != want {
+		t.Errorf("Validate() = %v, want %q", err, want)
+	}
+}
@@ -2,9 +2,7 @@ package cinemate
 
 import (
 	"encoding/xml"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+	"time"
 )
 
 const (
@@ -13,20 +11,22 @@ const (
 
 // API with apikey for use api.cinemate.cc
 type API struct {
-	apikey string
+	*Client
 }
 
 // APIResponse - Response from api.cinemate.cc
 type APIResponse struct {
-	Movies  []Movie  `xml:"movie,omitempty"`
-	Persons []Person `xml:"person,omitempty"`
+	Movies  []Movie  `xml:"movie,omitempty" json:"movie,omitempty"`
+	Persons []Person `xml:"person,omitempty" json:"person,omitempty"`
 }
 
-// APIErrorResponse - Error response from api.cinemate.cc
+// APIErrorResponse - Error response from api.cinemate.cc. Error document is
+// <error> root or <response> with <error> element
 type APIErrorResponse struct {
-	XMLName xml.Name `xml:"error"`
-	Error   string   `xml:"error,omitempty"`
-	Code    int64    `xml:"code,omitempty"`
+	XMLName xml.Name `json:"-"`
+	Error   string   `xml:"error,omitempty" json:"error,omitempty"`
+	Code    int64    `xml:"code,omitempty" json:"code,omitempty"`
+	Text    string   `xml:",chardata" json:"-"`
 }
 
 // Movie is response movie api from server. Now parse only xml
@@ -53,48 +53,48 @@ type APIErrorResponse struct {
 // cast                список актеров фильма, представленный списком тегов name с русским именами актеров и ID персоны
 // url                 ссылка на страницу фильма
 type Movie struct {
-	ID                int64    `xml:"id,omitempty"`
-	Type              string   `xml:"type,omitempty"`
-	TitleRussian      string   `xml:"title_russian"`
-	TitleOriginal     string   `xml:"title_original"`
-	TitleEnglish      string   `xml:"title_english,omitempty"`
-	Year              int64    `xml:"year,omitempty"`
-	Runtime           int64    `xml:"runtime,omitempty"`
-	Poster            image    `xml:"poster,omitempty"`
-	URL               string   `xml:"url,omitempty"`
-	Imdb              rating   `xml:"imdb,omitempty"`
-	Kinopoisk         rating   `xml:"kinopoisk,omitempty"`
-	Country           country  `xml:"country"`
-	Genre             genre    `xml:"genre"`
-	Description       string   `xml:"description,omitempty"`
-	Trailer           string   `xml:"trailer,omitempty"`
-	ReleaseDateWorld  string   `xml:"release_date_world,omitempty"`
-	ReleaseDateRussia string   `xml:"release_date_russia,omitempty"`
-	Director          Person   `xml:"director>person,omitempty"`
-	Cast              []Person `xml:"cast>person,omitempty"`
+	ID                int64    `xml:"id,omitempty" json:"id,omitempty"`
+	Type              string   `xml:"type,omitempty" json:"type,omitempty"`
+	TitleRussian      string   `xml:"title_russian" json:"title_russian"`
+	TitleOriginal     string   `xml:"title_original" json:"title_original"`
+	TitleEnglish      string   `xml:"title_english,omitempty" json:"title_english,omitempty"`
+	Year              int64    `xml:"year,omitempty" json:"year,omitempty"`
+	Runtime           int64    `xml:"runtime,omitempty" json:"runtime,omitempty"`
+	Poster            image    `xml:"poster,omitempty" json:"poster,omitempty"`
+	URL               string   `xml:"url,omitempty" json:"url,omitempty"`
+	Imdb              rating   `xml:"imdb,omitempty" json:"imdb,omitempty"`
+	Kinopoisk         rating   `xml:"kinopoisk,omitempty" json:"kinopoisk,omitempty"`
+	Country           country  `xml:"country" json:"country"`
+	Genre             genre    `xml:"genre" json:"genre"`
+	Description       string   `xml:"description,omitempty" json:"description,omitempty"`
+	Trailer           string   `xml:"trailer,omitempty" json:"trailer,omitempty"`
+	ReleaseDateWorld  Date     `xml:"release_date_world,omitempty" json:"release_date_world,omitempty"`
+	ReleaseDateRussia Date     `xml:"release_date_russia,omitempty" json:"release_date_russia,omitempty"`
+	Director          Person   `xml:"director>person,omitempty" json:"-"`
+	Cast              []Person `xml:"cast>person,omitempty" json:"-"`
 }
 
 type image struct {
-	Small  urlStruct `xml:"small"`
-	Big    urlStruct `xml:"big"`
-	Medium urlStruct `xml:"medium"`
+	Small  urlStruct `xml:"small" json:"small"`
+	Big    urlStruct `xml:"big" json:"big"`
+	Medium urlStruct `xml:"medium" json:"medium"`
 }
 
 type urlStruct struct {
-	URL string `xml:"url,attr"`
+	URL string `xml:"url,attr" json:"url"`
 }
 
 type rating struct {
-	Rating float64 `xml:"rating,attr"`
-	Votes  int64   `xml:"votes,attr"`
+	Rating float64 `xml:"rating,attr" json:"rating"`
+	Votes  int64   `xml:"votes,attr" json:"votes"`
 }
 
 type country struct {
-	Name []string `xml:"name,omitempty"`
+	Name []string `xml:"name,omitempty" json:"name,omitempty"`
 }
 
 type genre struct {
-	Name []string `xml:"name,omitempty"`
+	Name []string `xml:"name,omitempty" json:"name,omitempty"`
 }
 
 // Person is response person api from server. Now parse only xml
@@ -104,42 +104,45 @@ type genre struct {
 // photo         включает в себя 3 тега со ссылками на фотографии разных размеров
 // url           ссылка на страницу персоны
 type Person struct {
-	ID           int64        `xml:"id,omitempty"`
-	Name         string       `xml:"name,omitempty"`
-	NameOriginal string       `xml:"name_original,omitempty"`
-	Photo        image        `xml:"photo,omitempty"`
-	URL          string       `xml:"url,omitempty"`
-	Movies       personMovies `xml:"movies,omitempty"`
+	ID           int64        `xml:"id,omitempty" json:"id,omitempty"`
+	Name         string       `xml:"name,omitempty" json:"name,omitempty"`
+	NameOriginal string       `xml:"name_original,omitempty" json:"name_original,omitempty"`
+	Photo        image        `xml:"photo,omitempty" json:"photo,omitempty"`
+	URL          string       `xml:"url,omitempty" json:"url,omitempty"`
+	Movies       personMovies `xml:"movies,omitempty" json:"movies,omitempty"`
 }
 
 type personMovies struct {
-	Director []Movie `xml:"director>movie,omitempty"`
-	Actor    []Movie `xml:"actor>movie,omitempty"`
+	Director []Movie `xml:"director>movie,omitempty" json:"-"`
+	Actor    []Movie `xml:"actor>movie,omitempty" json:"-"`
 }
 
 // CCRequest struct for make search request
+// From, To значения среза параметра OrderBy, передаются в формате ДД.ММ.ГГГГ по московскому времени
 type CCRequest struct {
 	ID      int64
-	Type    string
-	State   string
-	Mode    string
+	Type    MovieType
+	State   State
+	Mode    Mode
 	Year    int64
 	Genre   string
 	Country string
-	OrderBy string
-	Order   string
-	From    string
-	To      string
+	OrderBy OrderBy
+	Order   Order
+	From    time.Time
+	To      time.Time
 	Page    int64
 	PerPage int64
-	Format  string
+	Format  Format
 }
 
 // Account with passkey for access to account
-// Passkey - PASSKEY пользователя
+// Passkey - PASSKEY пользователя; у Account с CredentialsProvider текущий PASSKEY возвращает CurrentPasskey
 type Account struct {
-	XMLName xml.Name `xml:"response"`
-	Passkey string   `xml:"passkey,omitempty"`
+	XMLName xml.Name `xml:"response" json:"-"`
+	Passkey string   `xml:"passkey,omitempty" json:"passkey,omitempty"`
+	client  *Client  `xml:"-" json:"-"`
+	reauth  *reauth  `xml:"-" json:"-"`
 }
 
 // AccountProfile is response account api from server
@@ -154,70 +157,73 @@ type Account struct {
 // unread_updatelist_count число новых записей в ленте обновлений
 // subscription_count      общее число подписок в ленте обновлений
 type AccountProfile struct {
-	Username              string `xml:"username"`
-	Reputation            int64  `xml:"reputation"`
-	ReviewCount           int64  `xml:"review_count"`
-	GoldBadges            int64  `xml:"gold_badges"`
-	SilverBadges          int64  `xml:"silver_badges"`
-	BronzeBadges          int64  `xml:"bronze_badges"`
-	UnreadPmCount         int64  `xml:"unread_pm_count"`
-	UnreadForumCount      int64  `xml:"unread_forum_count"`
-	UnreadUpdatelistCount int64  `xml:"unread_updatelist_count"`
-	SubscriptionCount     int64  `xml:"subscription_count"`
+	Username              string `xml:"username" json:"username"`
+	Reputation            int64  `xml:"reputation" json:"reputation"`
+	ReviewCount           int64  `xml:"review_count" json:"review_count"`
+	GoldBadges            int64  `xml:"gold_badges" json:"gold_badges"`
+	SilverBadges          int64  `xml:"silver_badges" json:"silver_badges"`
+	BronzeBadges          int64  `xml:"bronze_badges" json:"bronze_badges"`
+	UnreadPmCount         int64  `xml:"unread_pm_count" json:"unread_pm_count"`
+	UnreadForumCount      int64  `xml:"unread_forum_count" json:"unread_forum_count"`
+	UnreadUpdatelistCount int64  `xml:"unread_updatelist_count" json:"unread_updatelist_count"`
+	SubscriptionCount     int64  `xml:"subscription_count" json:"subscription_count"`
 }
 
 // UpdateList Записи ленты обновлений пользователя
 // count число всех записей в ленте обновлений (новый)
 // item  запись ленты обновлений
 type UpdateList struct {
-	Count int64            `xml:"count"`
-	Items []updateListItem `xml:"item"`
+	Count int64            `xml:"count" json:"count"`
+	Items []UpdateListItem `xml:"item" json:"item"`
 }
 
-// ListItem запись ленты обновлений
+// UpdateListItem запись ленты обновлений
 // date	дата и время добавления записи в ленту обновлений пользователя в ISO формате
 // description	текстовое описание обновления
 // url	ссылка на обновление; переход по ссылке отмечает запись в ленте прочитанной и производит редирект на страницу с обновлением
 // new	флаг прочитанного обновления (1 - непрочтенное, 0 - прочтенное)
 // for_object	список объектов object, список объектов movie, person или comment, к которым привязано обновление
-type updateListItem struct {
-	Date        string           `xml:"date"`
-	Description string           `xml:"description"`
-	URL         string           `xml:"url"`
-	New         int64            `xml:"new"`
-	ForObject   updateListObject `xml:"for_object"`
+type UpdateListItem struct {
+	Date        Date             `xml:"date" json:"date"`
+	Description string           `xml:"description" json:"description"`
+	URL         string           `xml:"url" json:"url"`
+	New         int64            `xml:"new" json:"new"`
+	ForObject   UpdateListObject `xml:"for_object" json:"for_object"`
 }
 
-type updateListObject struct {
-	Movie   updateListItemObject `xml:"movie,omitempty"`
-	Person  updateListItemObject `xml:"person,omitempty"`
-	Comment updateListItemObject `xml:"comment,omitempty"`
+// UpdateListObject объекты movie, person или comment, к которым привязано обновление
+type UpdateListObject struct {
+	Movie   UpdateListItemObject `xml:"movie,omitempty" json:"movie,omitempty"`
+	Person  UpdateListItemObject `xml:"person,omitempty" json:"person,omitempty"`
+	Comment UpdateListItemObject `xml:"comment,omitempty" json:"comment,omitempty"`
 }
 
+// UpdateListItemObject объект обновления
+// id	ID объекта
 // title	строковое представление объекта
-// url	ссылка на объект обновления
-type updateListItemObject struct {
-	ID    int64  `xml:"id"`
-	Title string `xml:"title"`
+type UpdateListItemObject struct {
+	ID    int64  `xml:"id" json:"id"`
+	Title string `xml:"title" json:"title"`
 }
 
 // WatchList список объектов слежения пользователя
 // Каждый узел представляет собой объект слежения одного из типов: movie, person или comment
 type WatchList struct {
-	Comments []watchListObject `xml:"comment"`
-	Persons  []watchListObject `xml:"person"`
-	Movies   []watchListObject `xml:"movie"`
+	Comments []WatchListObject `xml:"comment" json:"comment"`
+	Persons  []WatchListObject `xml:"person" json:"person"`
+	Movies   []WatchListObject `xml:"movie" json:"movie"`
 }
 
+// WatchListObject объект слежения
 // date	дата и время добавления объекта в список слежения в ISO формате
 // name	строковое представление объекта слежения
 // description	описание подписки на объект
 // url	ссылка на объект слежения
-type watchListObject struct {
-	Date        string `xml:"date"`
-	Name        string `xml:"name"`
-	Description string `xml:"description"`
-	URL         string `xml:"url"`
+type WatchListObject struct {
+	Date        Date   `xml:"date" json:"date"`
+	Name        string `xml:"name" json:"name"`
+	Description string `xml:"description" json:"description"`
+	URL         string `xml:"url" json:"url"`
 }
 
 // Stats статистика сайта за последние сутки
@@ -226,15 +232,15 @@ type watchListObject struct {
 // comments_count число новых комментариев к отзывам
 // movies_count   число новых фильмов
 type Stats struct {
-	UsersCount    int64 `xml:"users_count"`
-	ReviewsCount  int64 `xml:"reviews_count"`
-	CommentsCount int64 `xml:"comments_count"`
-	MoviesCount   int64 `xml:"movies_count"`
+	UsersCount    int64 `xml:"users_count" json:"users_count"`
+	ReviewsCount  int64 `xml:"reviews_count" json:"reviews_count"`
+	CommentsCount int64 `xml:"comments_count" json:"comments_count"`
+	MoviesCount   int64 `xml:"movies_count" json:"movies_count"`
 }
 
 // Init CinemaCC to set API value
 func Init(apiKey string) *API {
-	return &API{apikey: apiKey}
+	return &API{Client: NewClient(WithAPIKey(apiKey))}
 }
 
 // InitAccount CinemaCC to set API value
@@ -242,19 +248,14 @@ func InitAccount(passKey string) *Account {
 	return &Account{Passkey: passKey}
 }
 
-func getXML(url string) ([]byte, error) {
-	fmt.Println(url)
-	resp, err := http.Get(url)
-	if err != nil {
-		return []byte{}, err
-	}
-	if resp.StatusCode != 200 {
-		return []byte{}, fmt.Errorf("Status Code %d received from cinemate.cc", resp.StatusCode)
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return []byte{}, err
+// Account return Account with passkey of Client
+func (c *Client) Account() *Account {
+	return &Account{Passkey: c.passkey, client: c}
+}
+
+func (acc *Account) getClient() *Client {
+	if acc.client == nil {
+		return defaultClient
 	}
-	return body, err
+	return acc.client
 }
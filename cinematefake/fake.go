@@ -0,0 +1,420 @@
+// Package cinematefake provide in-memory implementation of service
+// interfaces of cinemate for unit tests. Service answer from its data or
+// from programmed functions and record every call.
+//
+//	fake := cinematefake.New()
+//	fake.AddMovie(cinemate.Movie{ID: 1, TitleRussian: "Фильм"})
+//	fake.SetError("GetMovieSearch", cinemate.ErrRateLimited)
+//	run(fake) // code depending on cinemate.MovieService
+//	calls := fake.CallsTo("GetMovie")
+package cinematefake
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/serbe/cinemate"
+)
+
+// Call recorded call of Service
+// Method имя метода без суффикса Context, например "GetMovie"
+// Args   аргументы метода без context
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Service fake of cinemate.MovieService, PersonService, AccountService and
+// StatsService. Func fields, if set, replace answer from data of Service
+type Service struct {
+	MovieFunc        func(ctx context.Context, id int64) (cinemate.Movie, error)
+	MovieListFunc    func(ctx context.Context, ccr cinemate.CCRequest) ([]cinemate.Movie, error)
+	MovieSearchFunc  func(ctx context.Context, term string) ([]cinemate.Movie, error)
+	PersonFunc       func(ctx context.Context, id int64) (cinemate.Person, error)
+	PersonMoviesFunc func(ctx context.Context, id int64) ([]cinemate.Person, error)
+	PersonSearchFunc func(ctx context.Context, term string) ([]cinemate.Person, error)
+	ProfileFunc      func(ctx context.Context) (cinemate.AccountProfile, error)
+	UpdateListFunc   func(ctx context.Context, newonly bool) (cinemate.UpdateList, error)
+	WatchlistFunc    func(ctx context.Context) (cinemate.WatchList, error)
+	StatsFunc        func(ctx context.Context) (cinemate.Stats, error)
+	mu               sync.Mutex
+	movies           map[int64]cinemate.Movie
+	persons          map[int64]cinemate.Person
+	profile          cinemate.AccountProfile
+	updates          []cinemate.UpdateListItem
+	watchlist        cinemate.WatchList
+	stats            cinemate.Stats
+	errs             map[string]error
+	calls            []Call
+}
+
+var (
+	_ cinemate.MovieService   = (*Service)(nil)
+	_ cinemate.PersonService  = (*Service)(nil)
+	_ cinemate.AccountService = (*Service)(nil)
+	_ cinemate.StatsService   = (*Service)(nil)
+)
+
+// New create empty Service
+func New() *Service {
+	return &Service{
+		movies:  make(map[int64]cinemate.Movie),
+		persons: make(map[int64]cinemate.Person),
+		errs:    make(map[string]error),
+	}
+}
+
+// AddMovie add or replace movie
+func (s *Service) AddMovie(movies ...cinemate.Movie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range movies {
+		s.movies[m.ID] = m
+	}
+}
+
+// AddPerson add or replace person
+func (s *Service) AddPerson(persons ...cinemate.Person) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range persons {
+		s.persons[p.ID] = p
+	}
+}
+
+// SetProfile set profile of account
+func (s *Service) SetProfile(profile cinemate.AccountProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profile = profile
+}
+
+// AddUpdate add item to update list of account as unread
+func (s *Service) AddUpdate(items ...cinemate.UpdateListItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range items {
+		item.New = 1
+		s.updates = append(s.updates, item)
+	}
+}
+
+// SetWatchlist set watchlist of account
+func (s *Service) SetWatchlist(list cinemate.WatchList) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchlist = list
+}
+
+// SetStats set statistics of site
+func (s *Service) SetStats(stats cinemate.Stats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = stats
+}
+
+// SetError make method return err until SetError(method, nil). method is
+// name without suffix Context, for example "GetMovie"
+func (s *Service) SetError(method string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		delete(s.errs, method)
+		return
+	}
+	s.errs[method] = err
+}
+
+// Calls return all recorded calls in order
+func (s *Service) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsTo return recorded calls of method
+func (s *Service) CallsTo(method string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var calls []Call
+	for _, c := range s.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset clear recorded calls
+func (s *Service) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+}
+
+// record save call and return programmed error of method
+func (s *Service) record(method string, args ...interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, Call{Method: method, Args: args})
+	return s.errs[method]
+}
+
+// GetMovie implement cinemate.MovieService
+func (s *Service) GetMovie(id int64) (cinemate.Movie, error) {
+	return s.GetMovieContext(context.Background(), id)
+}
+
+// GetMovieContext implement cinemate.MovieService
+func (s *Service) GetMovieContext(ctx context.Context, id int64) (movie cinemate.Movie, err error) {
+	if err = s.record("GetMovie", id); err != nil {
+		return
+	}
+	if s.MovieFunc != nil {
+		return s.MovieFunc(ctx, id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	movie, ok := s.movies[id]
+	if !ok {
+		err = cinemate.ErrNotFound
+	}
+	return
+}
+
+// GetMovieList implement cinemate.MovieService
+func (s *Service) GetMovieList(ccr cinemate.CCRequest) ([]cinemate.Movie, error) {
+	return s.GetMovieListContext(context.Background(), ccr)
+}
+
+// GetMovieListContext implement cinemate.MovieService. Without MovieListFunc
+// return page Page of PerPage movies, 10 by default, filtered by Type and
+// Year of ccr and ordered by ID
+func (s *Service) GetMovieListContext(ctx context.Context, ccr cinemate.CCRequest) (movies []cinemate.Movie, err error) {
+	if err = s.record("GetMovieList", ccr); err != nil {
+		return
+	}
+	if s.MovieListFunc != nil {
+		return s.MovieListFunc(ctx, ccr)
+	}
+	if err = ccr.Validate(); err != nil {
+		return
+	}
+	movies = s.findMovies(func(m cinemate.Movie) bool {
+		return (ccr.Type == "" || m.Type == string(ccr.Type)) &&
+			(ccr.Year == 0 || m.Year == ccr.Year)
+	})
+	return paginate(movies, ccr.Page, ccr.PerPage), nil
+}
+
+// GetMovieSearch implement cinemate.MovieService
+func (s *Service) GetMovieSearch(term string) ([]cinemate.Movie, error) {
+	return s.GetMovieSearchContext(context.Background(), term)
+}
+
+// GetMovieSearchContext implement cinemate.MovieService. Without
+// MovieSearchFunc return movies with titles containing term
+func (s *Service) GetMovieSearchContext(ctx context.Context, term string) (movies []cinemate.Movie, err error) {
+	if err = s.record("GetMovieSearch", term); err != nil {
+		return
+	}
+	if s.MovieSearchFunc != nil {
+		return s.MovieSearchFunc(ctx, term)
+	}
+	return s.findMovies(func(m cinemate.Movie) bool {
+		return containsFold(m.TitleRussian, term) ||
+			containsFold(m.TitleOriginal, term) ||
+			containsFold(m.TitleEnglish, term)
+	}), nil
+}
+
+// GetPerson implement cinemate.PersonService
+func (s *Service) GetPerson(id int64) (cinemate.Person, error) {
+	return s.GetPersonContext(context.Background(), id)
+}
+
+// GetPersonContext implement cinemate.PersonService
+func (s *Service) GetPersonContext(ctx context.Context, id int64) (person cinemate.Person, err error) {
+	if err = s.record("GetPerson", id); err != nil {
+		return
+	}
+	if s.PersonFunc != nil {
+		return s.PersonFunc(ctx, id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	person, ok := s.persons[id]
+	if !ok {
+		err = cinemate.ErrNotFound
+	}
+	return
+}
+
+// GetPersonMovies implement cinemate.PersonService
+func (s *Service) GetPersonMovies(id int64) ([]cinemate.Person, error) {
+	return s.GetPersonMoviesContext(context.Background(), id)
+}
+
+// GetPersonMoviesContext implement cinemate.PersonService. Without
+// PersonMoviesFunc return person with Movies as added
+func (s *Service) GetPersonMoviesContext(ctx context.Context, id int64) (persons []cinemate.Person, err error) {
+	if err = s.record("GetPersonMovies", id); err != nil {
+		return
+	}
+	if s.PersonMoviesFunc != nil {
+		return s.PersonMoviesFunc(ctx, id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	person, ok := s.persons[id]
+	if !ok {
+		err = cinemate.ErrNotFound
+		return
+	}
+	persons = []cinemate.Person{person}
+	return
+}
+
+// GetPersonSearch implement cinemate.PersonService
+func (s *Service) GetPersonSearch(term string) ([]cinemate.Person, error) {
+	return s.GetPersonSearchContext(context.Background(), term)
+}
+
+// GetPersonSearchContext implement cinemate.PersonService. Without
+// PersonSearchFunc return persons with names containing term
+func (s *Service) GetPersonSearchContext(ctx context.Context, term string) (persons []cinemate.Person, err error) {
+	if err = s.record("GetPersonSearch", term); err != nil {
+		return
+	}
+	if s.PersonSearchFunc != nil {
+		return s.PersonSearchFunc(ctx, term)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	persons = make([]cinemate.Person, 0)
+	for _, p := range s.persons {
+		if containsFold(p.Name, term) || containsFold(p.NameOriginal, term) {
+			persons = append(persons, p)
+		}
+	}
+	sort.Slice(persons, func(i, j int) bool { return persons[i].ID < persons[j].ID })
+	return
+}
+
+// GetAccountProfile implement cinemate.AccountService
+func (s *Service) GetAccountProfile() (cinemate.AccountProfile, error) {
+	return s.GetAccountProfileContext(context.Background())
+}
+
+// GetAccountProfileContext implement cinemate.AccountService
+func (s *Service) GetAccountProfileContext(ctx context.Context) (profile cinemate.AccountProfile, err error) {
+	if err = s.record("GetAccountProfile"); err != nil {
+		return
+	}
+	if s.ProfileFunc != nil {
+		return s.ProfileFunc(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.profile, nil
+}
+
+// GetAccountUpdateList implement cinemate.AccountService
+func (s *Service) GetAccountUpdateList(newonly ...bool) (cinemate.UpdateList, error) {
+	return s.GetAccountUpdateListContext(context.Background(), newonly...)
+}
+
+// GetAccountUpdateListContext implement cinemate.AccountService. Without
+// UpdateListFunc return items added by AddUpdate, Count is number of unread
+// items. As real server, request does not mark items read
+func (s *Service) GetAccountUpdateListContext(ctx context.Context, newonly ...bool) (list cinemate.UpdateList, err error) {
+	only := len(newonly) == 0 || newonly[0]
+	if err = s.record("GetAccountUpdateList", only); err != nil {
+		return
+	}
+	if s.UpdateListFunc != nil {
+		return s.UpdateListFunc(ctx, only)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range s.updates {
+		if item.New == 1 {
+			list.Count++
+		}
+		if !only || item.New == 1 {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return
+}
+
+// GetAccountWatchlist implement cinemate.AccountService
+func (s *Service) GetAccountWatchlist() (cinemate.WatchList, error) {
+	return s.GetAccountWatchlistContext(context.Background())
+}
+
+// GetAccountWatchlistContext implement cinemate.AccountService
+func (s *Service) GetAccountWatchlistContext(ctx context.Context) (list cinemate.WatchList, err error) {
+	if err = s.record("GetAccountWatchlist"); err != nil {
+		return
+	}
+	if s.WatchlistFunc != nil {
+		return s.WatchlistFunc(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.watchlist, nil
+}
+
+// GetStatsNew implement cinemate.StatsService
+func (s *Service) GetStatsNew() (cinemate.Stats, error) {
+	return s.GetStatsNewContext(context.Background())
+}
+
+// GetStatsNewContext implement cinemate.StatsService
+func (s *Service) GetStatsNewContext(ctx context.Context) (stats cinemate.Stats, err error) {
+	if err = s.record("GetStatsNew"); err != nil {
+		return
+	}
+	if s.StatsFunc != nil {
+		return s.StatsFunc(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats, nil
+}
+
+func (s *Service) findMovies(match func(cinemate.Movie) bool) []cinemate.Movie {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	movies := make([]cinemate.Movie, 0)
+	for _, m := range s.movies {
+		if match(m) {
+			movies = append(movies, m)
+		}
+	}
+	sort.Slice(movies, func(i, j int) bool { return movies[i].ID < movies[j].ID })
+	return movies
+}
+
+// paginate return page of movies with perPage movies, 10 if perPage is 0
+func paginate(movies []cinemate.Movie, page, perPage int64) []cinemate.Movie {
+	if perPage == 0 {
+		perPage = 10
+	}
+	start := page * perPage
+	if start >= int64(len(movies)) {
+		return make([]cinemate.Movie, 0)
+	}
+	end := start + perPage
+	if end > int64(len(movies)) {
+		end = int64(len(movies))
+	}
+	return movies[start:end]
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,159 @@
+package cinematefake
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/serbe/cinemate"
+)
+
+func TestServiceMovies(t *testing.T) {
+	s := New()
+	s.AddMovie(
+		cinemate.Movie{ID: 2, TitleRussian: "Криминальное чтиво", Year: 1994, Type: "movie"},
+		cinemate.Movie{ID: 1, TitleRussian: "Пираты Карибского моря", Year: 2003, Type: "movie"},
+		cinemate.Movie{ID: 3, TitleRussian: "Остаться в живых", Year: 2004, Type: "serial"},
+	)
+	if m, err := s.GetMovie(2); err != nil || m.Year != 1994 {
+		t.Errorf("GetMovie(2) = %+v, %v", m, err)
+	}
+	if _, err := s.GetMovie(4); !errors.Is(err, cinemate.ErrNotFound) {
+		t.Errorf("GetMovie(4) err = %v, want ErrNotFound", err)
+	}
+	movies, err := s.GetMovieList(cinemate.CCRequest{Type: cinemate.MovieTypeMovie})
+	if err != nil || len(movies) != 2 || movies[0].ID != 1 || movies[1].ID != 2 {
+		t.Errorf("GetMovieList(movie) = %+v, %v", movies, err)
+	}
+	if _, err := s.GetMovieList(cinemate.CCRequest{PerPage: 100}); err == nil {
+		t.Error("GetMovieList() of invalid request err = nil")
+	}
+	movies, err = s.GetMovieSearch("ЧТИВО")
+	if err != nil || len(movies) != 1 || movies[0].ID != 2 {
+		t.Errorf("GetMovieSearch() = %+v, %v", movies, err)
+	}
+	movies, err = s.GetMovieSearch("матрица")
+	if err != nil || movies == nil || len(movies) != 0 {
+		t.Errorf("GetMovieSearch() = %#v, %v, want empty", movies, err)
+	}
+}
+
+func TestServiceMovieListPages(t *testing.T) {
+	s := New()
+	for id := int64(1); id <= 25; id++ {
+		s.AddMovie(cinemate.Movie{ID: id})
+	}
+	ccr := cinemate.CCRequest{PerPage: 10}
+	var ids []int64
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pages never end")
+		}
+		movies, err := s.GetMovieList(ccr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range movies {
+			ids = append(ids, m.ID)
+		}
+		if int64(len(movies)) < ccr.PerPage {
+			break
+		}
+		ccr.Page++
+	}
+	if len(ids) != 25 || ids[0] != 1 || ids[24] != 25 {
+		t.Errorf("ids = %v, want 1..25", ids)
+	}
+	movies, _ := s.GetMovieList(cinemate.CCRequest{Page: 1})
+	if len(movies) != 10 || movies[0].ID != 11 {
+		t.Errorf("page 1 of default size = %+v", movies)
+	}
+	movies, _ = s.GetMovieList(cinemate.CCRequest{Page: 3})
+	if movies == nil || len(movies) != 0 {
+		t.Errorf("page after end = %#v, want empty", movies)
+	}
+}
+
+func TestServicePersons(t *testing.T) {
+	s := New()
+	s.AddPerson(cinemate.Person{ID: 3971, Name: "Квентин Тарантино", NameOriginal: "Quentin Tarantino"})
+	if p, err := s.GetPerson(3971); err != nil || p.NameOriginal != "Quentin Tarantino" {
+		t.Errorf("GetPerson() = %+v, %v", p, err)
+	}
+	if _, err := s.GetPersonMovies(1); !errors.Is(err, cinemate.ErrNotFound) {
+		t.Errorf("GetPersonMovies(1) err = %v, want ErrNotFound", err)
+	}
+	if persons, err := s.GetPersonMovies(3971); err != nil || len(persons) != 1 {
+		t.Errorf("GetPersonMovies() = %+v, %v", persons, err)
+	}
+	if persons, err := s.GetPersonSearch("tarantino"); err != nil || len(persons) != 1 {
+		t.Errorf("GetPersonSearch() = %+v, %v", persons, err)
+	}
+}
+
+func TestServiceUpdateList(t *testing.T) {
+	s := New()
+	s.AddUpdate(cinemate.UpdateListItem{URL: "a"}, cinemate.UpdateListItem{URL: "b"})
+	for i := 0; i < 2; i++ {
+		list, err := s.GetAccountUpdateList()
+		if err != nil || list.Count != 2 || len(list.Items) != 2 || list.Items[0].New != 1 {
+			t.Errorf("read %d: GetAccountUpdateList() = %+v, %v, want 2 unread items", i, list, err)
+		}
+	}
+	list, err := s.GetAccountUpdateList(false)
+	if err != nil || list.Count != 2 || len(list.Items) != 2 {
+		t.Errorf("GetAccountUpdateList(false) = %+v, %v", list, err)
+	}
+	calls := s.CallsTo("GetAccountUpdateList")
+	if len(calls) != 3 || calls[0].Args[0] != true || calls[2].Args[0] != false {
+		t.Errorf("calls = %+v", calls)
+	}
+}
+
+func TestServiceAccountAndStats(t *testing.T) {
+	s := New()
+	s.SetProfile(cinemate.AccountProfile{Username: "user"})
+	s.SetWatchlist(cinemate.WatchList{Movies: []cinemate.WatchListObject{{Name: "Фильм"}}})
+	s.SetStats(cinemate.Stats{UsersCount: 4})
+	if p, err := s.GetAccountProfile(); err != nil || p.Username != "user" {
+		t.Errorf("GetAccountProfile() = %+v, %v", p, err)
+	}
+	if w, err := s.GetAccountWatchlist(); err != nil || len(w.Movies) != 1 {
+		t.Errorf("GetAccountWatchlist() = %+v, %v", w, err)
+	}
+	if st, err := s.GetStatsNew(); err != nil || st.UsersCount != 4 {
+		t.Errorf("GetStatsNew() = %+v, %v", st, err)
+	}
+}
+
+func TestServiceErrorsFuncsAndCalls(t *testing.T) {
+	s := New()
+	s.AddMovie(cinemate.Movie{ID: 1})
+	s.SetError("GetMovie", cinemate.ErrRateLimited)
+	if _, err := s.GetMovie(1); !errors.Is(err, cinemate.ErrRateLimited) {
+		t.Errorf("GetMovie() err = %v, want ErrRateLimited", err)
+	}
+	s.SetError("GetMovie", nil)
+	if _, err := s.GetMovie(1); err != nil {
+		t.Errorf("GetMovie() after SetError(nil) err = %v", err)
+	}
+	s.MovieSearchFunc = func(ctx context.Context, term string) ([]cinemate.Movie, error) {
+		return []cinemate.Movie{{ID: 42}}, nil
+	}
+	if movies, _ := s.GetMovieSearch("x"); len(movies) != 1 || movies[0].ID != 42 {
+		t.Errorf("GetMovieSearch() with func = %+v", movies)
+	}
+	want := []Call{
+		{Method: "GetMovie", Args: []interface{}{int64(1)}},
+		{Method: "GetMovie", Args: []interface{}{int64(1)}},
+		{Method: "GetMovieSearch", Args: []interface{}{"x"}},
+	}
+	if calls := s.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("Calls() = %+v, want %+v", calls, want)
+	}
+	s.Reset()
+	if calls := s.Calls(); len(calls) != 0 {
+		t.Errorf("Calls() after Reset = %+v", calls)
+	}
+}
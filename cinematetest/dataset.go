@@ -0,0 +1,72 @@
+package cinematetest
+
+import "github.com/serbe/cinemate"
+
+// SeedPasskey PASSKEY of user of Seed
+const SeedPasskey = "0123456789abcdef0123456789abcdef01234567"
+
+// Seed return small Dataset with movies, persons and one user
+// username: user, password: password, passkey: SeedPasskey
+func Seed() Dataset {
+	director := cinemate.Person{ID: 3971, Name: "Квентин Тарантино", NameOriginal: "Quentin Tarantino", URL: "http://cinemate.cc/person/3971/"}
+	actor := cinemate.Person{ID: 68675, Name: "Джейк Джилленхол", NameOriginal: "Jake Gyllenhaal", URL: "http://cinemate.cc/person/68675/"}
+	movie1 := cinemate.Movie{
+		ID:                68675,
+		Type:              string(cinemate.MovieTypeMovie),
+		TitleRussian:      "Криминальная фишка от Генри",
+		TitleOriginal:     "Henry's Crime",
+		Year:              2010,
+		Runtime:           108,
+		ReleaseDateWorld:  date("2010-09-18"),
+		ReleaseDateRussia: date("2011-04-14"),
+		Director:          director,
+		Cast:              []cinemate.Person{actor},
+		URL:               "http://cinemate.cc/movie/68675/",
+	}
+	movie1.Imdb.Rating = 6.0
+	movie1.Imdb.Votes = 12000
+	movie1.Country.Name = []string{"США"}
+	movie1.Genre.Name = []string{"Комедия", "Криминал"}
+	movie2 := cinemate.Movie{
+		ID:                2,
+		Type:              string(cinemate.MovieTypeMovie),
+		TitleRussian:      "Криминальное чтиво",
+		TitleOriginal:     "Pulp Fiction",
+		Year:              1994,
+		Runtime:           154,
+		ReleaseDateWorld:  date("1994-05-21"),
+		ReleaseDateRussia: date("1995-02-01"),
+		Director:          director,
+		URL:               "http://cinemate.cc/movie/2/",
+	}
+	movie2.Imdb.Rating = 8.9
+	movie2.Imdb.Votes = 1500000
+	movie2.Country.Name = []string{"США"}
+	movie2.Genre.Name = []string{"Криминал", "Драма"}
+	return Dataset{
+		APIKeys: []string{"APIKEY"},
+		Movies:  []cinemate.Movie{movie1, movie2},
+		Persons: []cinemate.Person{director, actor},
+		Users: []User{{
+			Username: "user",
+			Password: "password",
+			Passkey:  SeedPasskey,
+			Profile:  cinemate.AccountProfile{Username: "user", Reputation: 10},
+			Updates: []cinemate.UpdateListItem{{
+				Date:        date("2011-04-14T12:00:00"),
+				Description: "Новый фильм в кинотеатрах",
+				URL:         "http://cinemate.cc/movie/68675/",
+				New:         1,
+			}},
+		}},
+		Stats: cinemate.Stats{UsersCount: 4, ReviewsCount: 2, CommentsCount: 10, MoviesCount: 1},
+	}
+}
+
+func date(s string) cinemate.Date {
+	d, err := cinemate.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
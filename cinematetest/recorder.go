@@ -0,0 +1,274 @@
+package cinematetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/serbe/cinemate"
+)
+
+// Redacted заменяет значения секретных параметров в записанных ответах
+const Redacted = "REDACTED"
+
+// RedactedPasskey заменяет PASSKEY в записанных ответах account.auth, чтобы
+// воспроизведенный ответ проходил проверку формата PASSKEY
+const RedactedPasskey = "0000000000000000000000000000000000000000"
+
+// secretParams query parameters redacted in fixtures
+var secretParams = []string{"apikey", "passkey", "password"}
+
+// ErrNoFixture запрос отсутствует в записанных ответах в режиме Strict
+var ErrNoFixture = errors.New("cinematetest: no fixture for request")
+
+// Mode of Recorder
+type Mode int
+
+// Modes of Recorder
+const (
+	// ModeReplay ответы читаются из файлов
+	ModeReplay Mode = iota
+	// ModeRecord запросы выполняются через Transport, ответы записываются в файлы
+	ModeRecord
+)
+
+// Recorder is http.RoundTripper which record responses of real server to
+// fixture files and replay them. Requests are matched by method, url and
+// form body of POST requests. Values of apikey, passkey and password are
+// redacted in fixtures and cookies and authentication headers of responses
+// are dropped, so files are safe to commit
+// Dir       каталог файлов с ответами
+// Mode      режим записи или воспроизведения
+// Strict    запрос без записанного ответа возвращает ErrNoFixture, иначе выполняется через Transport
+// Transport транспорт для реальных запросов, по умолчанию http.DefaultTransport
+type Recorder struct {
+	Dir       string
+	Mode      Mode
+	Strict    bool
+	Transport http.RoundTripper
+}
+
+// fixture recorded request and response
+type fixture struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        string      `json:"body"`
+}
+
+// NewRecorder create Recorder with fixtures in dir
+func NewRecorder(dir string, mode Mode) *Recorder {
+	return &Recorder{Dir: dir, Mode: mode, Strict: true}
+}
+
+// ReplayClient return Client replaying fixtures of dir by Recorder in strict
+// mode, with apikey APIKEY and without rate limit. opts are applied after
+// defaults, so WithHTTPClient with Recorder in ModeRecord and WithBaseURL of
+// real server record fixtures
+func ReplayClient(dir string, opts ...cinemate.Option) *cinemate.Client {
+	defaults := []cinemate.Option{
+		cinemate.WithBaseURL("http://api.cinemate.test"),
+		cinemate.WithHTTPClient(&http.Client{Transport: NewRecorder(dir, ModeReplay)}),
+		cinemate.WithRateLimiter(nil),
+		cinemate.WithAPIKey("APIKEY"),
+	}
+	return cinemate.NewClient(append(defaults, opts...)...)
+}
+
+// RoundTrip record or replay request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	redacted := redactURL(req.URL)
+	form, err := redactForm(req)
+	if err != nil {
+		return nil, err
+	}
+	name := r.fixturePath(req.Method, redacted, form)
+	if r.Mode == ModeRecord {
+		return r.record(req, name, redacted, form)
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		if os.IsNotExist(err) && !r.Strict {
+			return r.transport().RoundTrip(req)
+		}
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s %s", ErrNoFixture, req.Method, redacted)
+		}
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("cinematetest: fixture %s: %w", name, err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header,
+		Body:          ioutil.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) record(req *http.Request, name, redacted, form string) (*http.Response, error) {
+	resp, err := r.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	// redacted body may differ in length, RoundTrip set length of replayed body
+	header := redactHeader(resp.Header)
+	header.Del("Content-Length")
+	f := fixture{
+		Method:      req.Method,
+		URL:         redacted,
+		RequestBody: form,
+		Status:      resp.StatusCode,
+		Header:      header,
+		Body:        redactBody(string(body)),
+	}
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(name, data.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *Recorder) transport() http.RoundTripper {
+	if r.Transport != nil {
+		return r.Transport
+	}
+	return http.DefaultTransport
+}
+
+// fixturePath return file name of fixture: api method and hash of request
+func (r *Recorder) fixturePath(method, redacted, form string) string {
+	u, _ := url.Parse(redacted)
+	key := method + " " + redacted
+	if form != "" {
+		key += "\n" + form
+	}
+	sum := sha256.Sum256([]byte(key))
+	name := strings.Trim(strings.Replace(u.Path, "/", "_", -1), "_")
+	if name == "" {
+		name = "root"
+	}
+	return filepath.Join(r.Dir, name+"-"+hex.EncodeToString(sum[:6])+".json")
+}
+
+// redactURL return url without host and with redacted secrets
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	redactValues(q)
+	return (&url.URL{Path: u.Path, RawQuery: q.Encode()}).String()
+}
+
+// redactForm read form body of request, restore body for transport and
+// return encoded form with redacted secrets
+func redactForm(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return string(data), nil
+	}
+	redactValues(form)
+	return form.Encode(), nil
+}
+
+func redactValues(v url.Values) {
+	for _, k := range secretParams {
+		if v.Get(k) != "" {
+			v.Set(k, Redacted)
+		}
+	}
+}
+
+// secretHeaders response headers dropped from fixtures: cookies and
+// authentication of session, and Date which changes on every record
+var secretHeaders = []string{
+	"Set-Cookie",
+	"Set-Cookie2",
+	"Authorization",
+	"Proxy-Authorization",
+	"WWW-Authenticate",
+	"Proxy-Authenticate",
+	"Date",
+}
+
+// redactHeader return copy of header without secretHeaders
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range secretHeaders {
+		h.Del(k)
+	}
+	return h
+}
+
+// bodySecret pattern of secret value in body and its replacement
+type bodySecret struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// bodySecrets match values of secretParams in XML elements, JSON strings and
+// query of links in body. Passkey is replaced by RedactedPasskey
+var bodySecrets = func() []bodySecret {
+	var list []bodySecret
+	for _, k := range secretParams {
+		repl := Redacted
+		if k == "passkey" {
+			repl = RedactedPasskey
+		}
+		list = append(list,
+			bodySecret{regexp.MustCompile(`(<` + k + `>)[^<]*(</` + k + `>)`), "${1}" + repl + "${2}"},
+			bodySecret{regexp.MustCompile(`("` + k + `"\s*:\s*")[^"]*(")`), "${1}" + repl + "${2}"},
+			bodySecret{regexp.MustCompile(`([?&;]` + k + `=)[^&"<\s]*`), "${1}" + repl},
+		)
+	}
+	return list
+}()
+
+// redactBody replace values of secret parameters in body
+func redactBody(body string) string {
+	for _, s := range bodySecrets {
+		body = s.re.ReplaceAllString(body, s.repl)
+	}
+	return body
+}
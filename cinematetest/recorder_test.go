@@ -0,0 +1,139 @@
+package cinematetest
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/serbe/cinemate"
+)
+
+// record rewrite testdata fixtures from seeded server:
+// go test ./cinematetest -run TestRecorderReplay -record
+var record = flag.Bool("record", false, "record fixtures in testdata from seeded server")
+
+// replayClient return client replaying fixtures of testdata in strict mode,
+// or recording them from seeded server with -record
+func replayClient(t *testing.T, format cinemate.Format) *cinemate.Client {
+	t.Helper()
+	if !*record {
+		return ReplayClient("testdata", cinemate.WithFormat(format))
+	}
+	srv := NewServer(Seed())
+	t.Cleanup(srv.Close)
+	rec := &Recorder{Dir: "testdata", Mode: ModeRecord, Transport: srv.Server.Client().Transport}
+	return ReplayClient("testdata",
+		cinemate.WithFormat(format),
+		cinemate.WithBaseURL(srv.URL),
+		cinemate.WithHTTPClient(&http.Client{Transport: rec}),
+	)
+}
+
+func TestRecorderReplay(t *testing.T) {
+	for _, format := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			c := replayClient(t, format)
+			movie, err := c.GetMovie(68675)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if movie.TitleRussian != "Криминальная фишка от Генри" || movie.TitleOriginal != "Henry's Crime" || movie.Year != 2010 {
+				t.Errorf("movie = %+v", movie)
+			}
+			if movie.ReleaseDateRussia.Raw != "2011-04-14" || movie.Imdb.Rating != 6.0 {
+				t.Errorf("release date %+v, imdb %+v", movie.ReleaseDateRussia, movie.Imdb)
+			}
+			if len(movie.Genre.Name) != 2 || movie.Genre.Name[0] != "Комедия" {
+				t.Errorf("genre = %+v", movie.Genre)
+			}
+			person, err := c.GetPerson(3971)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if person.ID != 3971 || person.Name != "Квентин Тарантино" {
+				t.Errorf("person = %+v", person)
+			}
+			acc, err := c.Login(context.Background(), "user", "password")
+			if err != nil {
+				t.Fatal(err)
+			}
+			list, err := acc.GetAccountUpdateList()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if list.Count != 1 || len(list.Items) != 1 {
+				t.Fatalf("update list = %+v", list)
+			}
+			item := list.Items[0]
+			if item.Date.Raw != "2011-04-14T12:00:00" || item.URL != "http://cinemate.cc/movie/68675/" || item.New != 1 {
+				t.Errorf("update = %+v", item)
+			}
+		})
+	}
+}
+
+func TestRecorderStrict(t *testing.T) {
+	c := replayClient(t, cinemate.FormatXML)
+	if *record {
+		t.Skip("recording")
+	}
+	if _, err := c.GetMovie(1); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("err = %v, want ErrNoFixture", err)
+	}
+}
+
+func TestRecorderRedact(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<response><id>1</id><apikey>1</apikey><passkey>abc</passkey>` +
+			`<url>http://cinemate.cc/movie.list?apikey=1&amp;passkey=abc&amp;year=1</url></response>`))
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	rec := NewRecorder(dir, ModeRecord)
+	resp, err := (&http.Client{Transport: rec}).Get(srv.URL + "/movie?apikey=1&id=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	data, err := ioutil.ReadFile(rec.fixturePath("GET", "/movie?apikey="+Redacted+"&id=1", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture := string(data)
+	if strings.Contains(fixture, "Set-Cookie") || strings.Contains(fixture, "secret") {
+		t.Errorf("cookie recorded: %s", fixture)
+	}
+	if strings.Contains(fixture, "Content-Length") {
+		t.Errorf("length of original body recorded: %s", fixture)
+	}
+	for _, want := range []string{
+		`<id>1</id>`,
+		`<apikey>REDACTED</apikey>`,
+		`<passkey>` + RedactedPasskey + `</passkey>`,
+		`?apikey=REDACTED&amp;passkey=` + RedactedPasskey + `&amp;year=1`,
+	} {
+		if !strings.Contains(fixture, want) {
+			t.Errorf("fixture has not %s: %s", want, fixture)
+		}
+	}
+	rec.Mode = ModeReplay
+	resp, err = (&http.Client{Transport: rec}).Get(srv.URL + "/movie?apikey=2&id=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ContentLength != int64(len(body)) || resp.Header.Get("Content-Length") != "" {
+		t.Errorf("replayed length %d, header %q, want %d", resp.ContentLength, resp.Header.Get("Content-Length"), len(body))
+	}
+}
@@ -0,0 +1,492 @@
+// Package cinematetest provide fake api.cinemate.cc server for tests. Server
+// implement all methods of api over in-memory Dataset and return error
+// documents the same way as real server.
+package cinematetest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/serbe/cinemate"
+)
+
+// Dataset data of fake server
+// APIKeys ключи разработчика, принимаемые сервером; пустой список - принимается любой ключ
+// Movies  фильмы
+// Persons персоны
+// Users   пользователи
+// Stats   статистика сайта
+type Dataset struct {
+	APIKeys []string
+	Movies  []cinemate.Movie
+	Persons []cinemate.Person
+	Users   []User
+	Stats   cinemate.Stats
+}
+
+// User account of fake server
+type User struct {
+	Username  string
+	Password  string
+	Passkey   string
+	Profile   cinemate.AccountProfile
+	Updates   []cinemate.UpdateListItem
+	Watchlist cinemate.WatchList
+}
+
+// Server fake api.cinemate.cc server
+type Server struct {
+	*httptest.Server
+	mu   sync.Mutex
+	data Dataset
+}
+
+// response root element of documents of server
+type response struct {
+	XMLName xml.Name `xml:"response" json:"-"`
+	cinemate.APIResponse
+}
+
+// errorDocument error document of server
+type errorDocument struct {
+	XMLName xml.Name `xml:"error" json:"-"`
+	Error   string   `xml:"error" json:"error"`
+	Code    int64    `xml:"code" json:"code"`
+}
+
+// NewServer start fake server with data. Server must be closed by Close
+func NewServer(data Dataset) *Server {
+	s := &Server{data: data}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/movie", s.withAPIKey(s.movie))
+	mux.HandleFunc("/movie.list", s.withAPIKey(s.movieList))
+	mux.HandleFunc("/movie.search", s.withAPIKey(s.movieSearch))
+	mux.HandleFunc("/person", s.withAPIKey(s.person))
+	mux.HandleFunc("/person.movies", s.withAPIKey(s.personMovies))
+	mux.HandleFunc("/person.search", s.withAPIKey(s.personSearch))
+	mux.HandleFunc("/account.auth", s.accountAuth)
+	mux.HandleFunc("/account.profile", s.withPasskey(s.accountProfile))
+	mux.HandleFunc("/account.updatelist", s.withPasskey(s.accountUpdateList))
+	mux.HandleFunc("/account.watchlist", s.withPasskey(s.accountWatchlist))
+	mux.HandleFunc("/stats.new", s.statsNew)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Client return cinemate.Client for server without rate limit and with first
+// api key of Dataset. opts are applied after default options
+func (s *Server) Client(opts ...cinemate.Option) *cinemate.Client {
+	s.mu.Lock()
+	apiKey := "apikey"
+	if len(s.data.APIKeys) > 0 {
+		apiKey = s.data.APIKeys[0]
+	}
+	s.mu.Unlock()
+	defaults := []cinemate.Option{
+		cinemate.WithBaseURL(s.URL),
+		cinemate.WithHTTPClient(s.Server.Client()),
+		cinemate.WithRateLimiter(nil),
+		cinemate.WithAPIKey(apiKey),
+	}
+	return cinemate.NewClient(append(defaults, opts...)...)
+}
+
+// AddMovie add movie to dataset
+func (s *Server) AddMovie(movie cinemate.Movie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Movies = append(s.data.Movies, movie)
+}
+
+// AddPerson add person to dataset
+func (s *Server) AddPerson(person cinemate.Person) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Persons = append(s.data.Persons, person)
+}
+
+// AddUser add user to dataset
+func (s *Server) AddUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Users = append(s.data.Users, user)
+}
+
+// AddUpdate add item to update list of user with passkey
+func (s *Server) AddUpdate(passkey string, item cinemate.UpdateListItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u := s.user(passkey); u != nil {
+		u.Updates = append(u.Updates, item)
+	}
+}
+
+// SetPasskey replace passkey of user, old passkey become invalid
+func (s *Server) SetPasskey(username, passkey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.data.Users {
+		if s.data.Users[i].Username == username {
+			s.data.Users[i].Passkey = passkey
+		}
+	}
+}
+
+// SetStats set statistics of site
+func (s *Server) SetStats(stats cinemate.Stats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Stats = stats
+}
+
+func (s *Server) withAPIKey(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.FormValue("apikey")
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if key == "" || (len(s.data.APIKeys) > 0 && !contains(s.data.APIKeys, key)) {
+			writeError(w, r, http.StatusForbidden, "Invalid apikey")
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (s *Server) withPasskey(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.user(r.FormValue("passkey")) == nil {
+			writeError(w, r, http.StatusForbidden, "Invalid passkey")
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (s *Server) movie(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	for _, m := range s.data.Movies {
+		if m.ID == id {
+			writeResponse(w, r, cinemate.APIResponse{Movies: []cinemate.Movie{m}})
+			return
+		}
+	}
+	writeError(w, r, http.StatusNotFound, "Movie not found")
+}
+
+func (s *Server) movieList(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.FormValue("page"))
+	perPage := 10
+	if v := r.FormValue("per_page"); v != "" {
+		perPage, _ = strconv.Atoi(v)
+	}
+	if perPage < 1 || perPage > cinemate.MaxPerPage || page < 0 {
+		writeError(w, r, http.StatusBadRequest, "Invalid page or per_page")
+		return
+	}
+	orderBy := r.FormValue("order_by")
+	if orderBy == "" {
+		orderBy = string(cinemate.OrderByRuReleaseDate)
+	}
+	from, errFrom := parseBound(r.FormValue("from"))
+	to, errTo := parseBound(r.FormValue("to"))
+	if errFrom != nil || errTo != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid from or to")
+		return
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+	var movies []cinemate.Movie
+	for _, m := range s.data.Movies {
+		if !matchMovie(m, r) {
+			continue
+		}
+		d := movieDate(m, orderBy)
+		if (!from.IsZero() && d.Before(from)) || (!to.IsZero() && !d.Before(to)) {
+			continue
+		}
+		movies = append(movies, m)
+	}
+	if r.FormValue("mode") == string(cinemate.ModeBest) {
+		sort.SliceStable(movies, func(i, j int) bool {
+			return movies[i].Imdb.Rating > movies[j].Imdb.Rating
+		})
+	} else {
+		asc := r.FormValue("order") == string(cinemate.OrderAsc)
+		sort.SliceStable(movies, func(i, j int) bool {
+			di, dj := movieDate(movies[i], orderBy), movieDate(movies[j], orderBy)
+			if asc {
+				return di.Before(dj)
+			}
+			return dj.Before(di)
+		})
+	}
+	writeResponse(w, r, cinemate.APIResponse{Movies: paginate(movies, page, perPage)})
+}
+
+func (s *Server) movieSearch(w http.ResponseWriter, r *http.Request) {
+	term, year := splitYear(r.FormValue("term"))
+	var movies []cinemate.Movie
+	for _, m := range s.data.Movies {
+		if year != 0 && m.Year != year {
+			continue
+		}
+		if containsFold(m.TitleRussian, term) || containsFold(m.TitleOriginal, term) || containsFold(m.TitleEnglish, term) {
+			movies = append(movies, m)
+		}
+	}
+	writeResponse(w, r, cinemate.APIResponse{Movies: paginate(movies, 0, 10)})
+}
+
+func (s *Server) person(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	for _, p := range s.data.Persons {
+		if p.ID == id {
+			p.Movies.Director = nil
+			p.Movies.Actor = nil
+			writeResponse(w, r, cinemate.APIResponse{Persons: []cinemate.Person{p}})
+			return
+		}
+	}
+	writeError(w, r, http.StatusNotFound, "Person not found")
+}
+
+func (s *Server) personMovies(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	for _, p := range s.data.Persons {
+		if p.ID != id {
+			continue
+		}
+		p.Movies.Director = nil
+		p.Movies.Actor = nil
+		for _, m := range s.data.Movies {
+			if m.Director.ID == id {
+				p.Movies.Director = append(p.Movies.Director, m)
+			}
+			for _, c := range m.Cast {
+				if c.ID == id {
+					p.Movies.Actor = append(p.Movies.Actor, m)
+					break
+				}
+			}
+		}
+		writeResponse(w, r, cinemate.APIResponse{Persons: []cinemate.Person{p}})
+		return
+	}
+	writeError(w, r, http.StatusNotFound, "Person not found")
+}
+
+func (s *Server) personSearch(w http.ResponseWriter, r *http.Request) {
+	term := r.FormValue("term")
+	var persons []cinemate.Person
+	for _, p := range s.data.Persons {
+		if containsFold(p.Name, term) || containsFold(p.NameOriginal, term) {
+			p.Movies.Director = nil
+			p.Movies.Actor = nil
+			persons = append(persons, p)
+		}
+	}
+	if len(persons) > 10 {
+		persons = persons[:10]
+	}
+	writeResponse(w, r, cinemate.APIResponse{Persons: persons})
+}
+
+func (s *Server) accountAuth(w http.ResponseWriter, r *http.Request) {
+	username, password := r.FormValue("username"), r.FormValue("password")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.data.Users {
+		if u.Username == username && u.Password == password {
+			writeDocument(w, r, http.StatusOK, cinemate.Account{Passkey: u.Passkey})
+			return
+		}
+	}
+	writeError(w, r, http.StatusUnauthorized, "Invalid username or password")
+}
+
+func (s *Server) accountProfile(w http.ResponseWriter, r *http.Request) {
+	u := s.user(r.FormValue("passkey"))
+	writeDocument(w, r, http.StatusOK, struct {
+		XMLName xml.Name `xml:"response" json:"-"`
+		cinemate.AccountProfile
+	}{AccountProfile: u.Profile})
+}
+
+func (s *Server) accountUpdateList(w http.ResponseWriter, r *http.Request) {
+	u := s.user(r.FormValue("passkey"))
+	newOnly := r.FormValue("newonly") != "0"
+	list := cinemate.UpdateList{}
+	for _, item := range u.Updates {
+		if item.New == 1 {
+			list.Count++
+		}
+		if !newOnly || item.New == 1 {
+			list.Items = append(list.Items, item)
+		}
+	}
+	writeDocument(w, r, http.StatusOK, struct {
+		XMLName xml.Name `xml:"response" json:"-"`
+		cinemate.UpdateList
+	}{UpdateList: list})
+}
+
+func (s *Server) accountWatchlist(w http.ResponseWriter, r *http.Request) {
+	u := s.user(r.FormValue("passkey"))
+	writeDocument(w, r, http.StatusOK, struct {
+		XMLName xml.Name `xml:"response" json:"-"`
+		cinemate.WatchList
+	}{WatchList: u.Watchlist})
+}
+
+func (s *Server) statsNew(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeDocument(w, r, http.StatusOK, struct {
+		XMLName xml.Name `xml:"response" json:"-"`
+		cinemate.Stats
+	}{Stats: s.data.Stats})
+}
+
+// user return user with passkey or nil, s.mu must be locked
+func (s *Server) user(passkey string) *User {
+	if passkey == "" {
+		return nil
+	}
+	for i := range s.data.Users {
+		if s.data.Users[i].Passkey == passkey {
+			return &s.data.Users[i]
+		}
+	}
+	return nil
+}
+
+func matchMovie(m cinemate.Movie, r *http.Request) bool {
+	if v := r.FormValue("type"); v != "" && m.Type != v {
+		return false
+	}
+	if v := r.FormValue("year"); v != "" && strconv.FormatInt(m.Year, 10) != v {
+		return false
+	}
+	if v := r.FormValue("genre"); v != "" && !containsEqualFold(m.Genre.Name, v) {
+		return false
+	}
+	if v := r.FormValue("country"); v != "" && !containsEqualFold(m.Country.Name, v) {
+		return false
+	}
+	now := time.Now()
+	switch r.FormValue("state") {
+	case string(cinemate.StateSoon):
+		return m.ReleaseDateRussia.Time.After(now)
+	case string(cinemate.StateCinema):
+		d := m.ReleaseDateRussia.Time
+		return !d.IsZero() && !d.After(now) && d.After(now.AddDate(0, 0, -30))
+	}
+	return true
+}
+
+// movieDate return date of movie used for order_by, create_date is
+// approximated by ID of movie
+func movieDate(m cinemate.Movie, orderBy string) time.Time {
+	switch orderBy {
+	case string(cinemate.OrderByCreateDate):
+		return time.Unix(m.ID, 0)
+	case string(cinemate.OrderByReleaseDate):
+		return m.ReleaseDateWorld.Time
+	}
+	return m.ReleaseDateRussia.Time
+}
+
+func parseBound(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("02.01.2006", s, cinemate.Moscow)
+}
+
+func paginate(movies []cinemate.Movie, page, perPage int) []cinemate.Movie {
+	start := page * perPage
+	if start >= len(movies) {
+		return nil
+	}
+	end := start + perPage
+	if end > len(movies) {
+		end = len(movies)
+	}
+	return movies[start:end]
+}
+
+// splitYear split year from end of search term
+func splitYear(term string) (string, int64) {
+	term = strings.TrimSpace(term)
+	i := strings.LastIndex(term, " ")
+	if i < 0 {
+		return term, 0
+	}
+	year, err := strconv.ParseInt(term[i+1:], 10, 64)
+	if err != nil || year < 1800 || year > 3000 {
+		return term, 0
+	}
+	return strings.TrimSpace(term[:i]), year
+}
+
+func containsFold(s, substr string) bool {
+	return substr != "" && strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func containsEqualFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, resp cinemate.APIResponse) {
+	writeDocument(w, r, http.StatusOK, response{APIResponse: resp})
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	writeDocument(w, r, status, errorDocument{Error: msg, Code: int64(status)})
+}
+
+// writeDocument write v in format of request
+func writeDocument(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	var (
+		body []byte
+		err  error
+	)
+	if r.FormValue("format") == string(cinemate.FormatJSON) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		body, err = json.Marshal(v)
+	} else {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		body, err = xml.Marshal(v)
+		body = append([]byte(xml.Header), body...)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
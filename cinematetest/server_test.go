@@ -0,0 +1,191 @@
+package cinematetest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/serbe/cinemate"
+)
+
+// formats formats of server documents
+var formats = []cinemate.Format{cinemate.FormatXML, cinemate.FormatJSON}
+
+// get request server and return status and body
+func get(t *testing.T, s *Server, path string, q url.Values) (int, []byte) {
+	t.Helper()
+	resp, err := http.Get(s.URL + path + "?" + q.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
+
+// decode unmarshal body in format into v
+func decode(t *testing.T, format cinemate.Format, body []byte, v interface{}) {
+	t.Helper()
+	var err error
+	if format == cinemate.FormatJSON {
+		err = json.Unmarshal(body, v)
+	} else {
+		err = xml.Unmarshal(body, v)
+	}
+	if err != nil {
+		t.Fatalf("decode %s: %v", body, err)
+	}
+}
+
+func TestSplitYear(t *testing.T) {
+	tests := []struct {
+		term string
+		want string
+		year int64
+	}{
+		{"Пираты кариб 2003", "Пираты кариб", 2003},
+		{"Пираты кариб", "Пираты кариб", 0},
+		{"  чтиво 1994 ", "чтиво", 1994},
+		{"1994", "1994", 0},
+		{"Бегущий по лезвию 2049 42", "Бегущий по лезвию 2049 42", 0},
+		{"Вики 1700", "Вики 1700", 0},
+	}
+	for _, tt := range tests {
+		term, year := splitYear(tt.term)
+		if term != tt.want || year != tt.year {
+			t.Errorf("splitYear(%q) = %q, %d, want %q, %d", tt.term, term, year, tt.want, tt.year)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	movies := []cinemate.Movie{{ID: 1}, {ID: 2}, {ID: 3}}
+	tests := []struct {
+		page, perPage int
+		want          []int64
+	}{
+		{0, 10, []int64{1, 2, 3}},
+		{0, 2, []int64{1, 2}},
+		{1, 2, []int64{3}},
+		{2, 2, nil},
+		{1, 3, nil},
+	}
+	for _, tt := range tests {
+		if got := ids(paginate(movies, tt.page, tt.perPage)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("paginate(%d, %d) = %v, want %v", tt.page, tt.perPage, got, tt.want)
+		}
+	}
+}
+
+func TestServerMovieList(t *testing.T) {
+	s := NewServer(Seed())
+	defer s.Close()
+	tests := []struct {
+		name   string
+		params string
+		want   []int64
+	}{
+		{"default order", "", []int64{68675, 2}},
+		{"order asc", "order=asc", []int64{2, 68675}},
+		{"from inclusive", "from=14.04.2011", []int64{68675}},
+		{"after last", "from=15.04.2011", nil},
+		{"to inclusive", "to=01.02.1995", []int64{2}},
+		{"before first", "to=31.01.1995", nil},
+		{"one day", "from=14.04.2011&to=14.04.2011", []int64{68675}},
+		{"world release", "order_by=release_date&from=21.05.1994&to=21.05.1994", []int64{2}},
+		{"year", "year=1994", []int64{2}},
+		{"genre", "genre=комедия", []int64{68675}},
+		{"best", "mode=best", []int64{2, 68675}},
+		{"second page", "per_page=1&page=1", []int64{2}},
+		{"page after end", "per_page=1&page=2", nil},
+	}
+	for _, format := range formats {
+		for _, tt := range tests {
+			t.Run(string(format)+"/"+tt.name, func(t *testing.T) {
+				q, _ := url.ParseQuery(tt.params)
+				q.Set("apikey", "APIKEY")
+				q.Set("format", string(format))
+				status, body := get(t, s, "/movie.list", q)
+				if status != http.StatusOK {
+					t.Fatalf("status %d: %s", status, body)
+				}
+				var resp response
+				decode(t, format, body, &resp)
+				if got := ids(resp.Movies); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("movies = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	s := NewServer(Seed())
+	defer s.Close()
+	tests := []struct {
+		name   string
+		path   string
+		params string
+		status int
+		msg    string
+	}{
+		{"no apikey", "/movie", "id=2", http.StatusForbidden, "Invalid apikey"},
+		{"wrong apikey", "/movie.search", "apikey=KEY&term=чтиво", http.StatusForbidden, "Invalid apikey"},
+		{"wrong passkey", "/account.profile", "passkey=PASSKEY", http.StatusForbidden, "Invalid passkey"},
+		{"no passkey", "/account.watchlist", "", http.StatusForbidden, "Invalid passkey"},
+		{"movie not found", "/movie", "apikey=APIKEY&id=1", http.StatusNotFound, "Movie not found"},
+		{"person not found", "/person", "apikey=APIKEY&id=1", http.StatusNotFound, "Person not found"},
+		{"person movies not found", "/person.movies", "apikey=APIKEY&id=1", http.StatusNotFound, "Person not found"},
+		{"per_page", "/movie.list", "apikey=APIKEY&per_page=26", http.StatusBadRequest, "Invalid page or per_page"},
+		{"bad from", "/movie.list", "apikey=APIKEY&from=2011-04-14", http.StatusBadRequest, "Invalid from or to"},
+		{"bad login", "/account.auth", "username=user&password=bad", http.StatusUnauthorized, "Invalid username or password"},
+	}
+	for _, format := range formats {
+		for _, tt := range tests {
+			t.Run(string(format)+"/"+tt.name, func(t *testing.T) {
+				q, _ := url.ParseQuery(tt.params)
+				q.Set("format", string(format))
+				status, body := get(t, s, tt.path, q)
+				if status != tt.status {
+					t.Errorf("status = %d, want %d", status, tt.status)
+				}
+				var doc errorDocument
+				decode(t, format, body, &doc)
+				if doc.Error != tt.msg || doc.Code != int64(tt.status) {
+					t.Errorf("error document = %+v, want %q code %d", doc, tt.msg, tt.status)
+				}
+			})
+		}
+	}
+}
+
+func TestServerUpdateList(t *testing.T) {
+	s := NewServer(Seed())
+	defer s.Close()
+	for _, format := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			q := url.Values{"passkey": {SeedPasskey}, "format": {string(format)}}
+			_, body := get(t, s, "/account.updatelist", q)
+			var list cinemate.UpdateList
+			decode(t, format, body, &list)
+			if list.Count != 1 || len(list.Items) != 1 || list.Items[0].Date.Raw != "2011-04-14T12:00:00" {
+				t.Errorf("update list = %+v", list)
+			}
+		})
+	}
+}
+
+func ids(movies []cinemate.Movie) []int64 {
+	var ids []int64
+	for _, m := range movies {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
@@ -0,0 +1,284 @@
+package cinemate
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client for api.cinemate.cc. All endpoints use base URL, http client and
+// credentials from Client
+type Client struct {
+	baseURL      string
+	authURL      string
+	httpClient   *http.Client
+	userAgent    string
+	apikey       string
+	passkey      string
+	limiter      *RateLimiter
+	format       Format
+	cache        *cacheState
+	retry        *RetryPolicy
+	batchWorkers int
+	logger       *slog.Logger
+	hooks        []Hook
+}
+
+// Format of data returned by api server
+type Format string
+
+const (
+	// FormatXML формат xml (по умолчанию)
+	FormatXML Format = "xml"
+	// FormatJSON формат json
+	FormatJSON Format = "json"
+)
+
+// Option set parameter of Client
+type Option func(*Client)
+
+// WithBaseURL set base URL of api server, default http://api.cinemate.cc
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithAuthURL set base URL of account.auth used by Login, default base URL
+// of Client. Login send password in request body, https URL protect it in
+// transit
+func WithAuthURL(authURL string) Option {
+	return func(c *Client) {
+		c.authURL = authURL
+	}
+}
+
+// WithHTTPClient set http client used for requests, default http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserAgent set User-Agent header of requests
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithAPIKey set apikey - ключ разработчика
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apikey = apiKey
+	}
+}
+
+// WithPasskey set passkey - PASSKEY пользователя
+func WithPasskey(passKey string) Option {
+	return func(c *Client) {
+		c.passkey = passKey
+	}
+}
+
+// WithRateLimit set limit of requests per second and burst of requests
+// without wait, default 1 request per second
+func WithRateLimit(rate float64, burst int) Option {
+	return func(c *Client) {
+		c.limiter = NewRateLimiter(rate, burst)
+	}
+}
+
+// WithRateLimiter set RateLimiter shared with other clients, nil disable limit
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithFormat set format of data returned by api server, default FormatXML
+func WithFormat(format Format) Option {
+	return func(c *Client) {
+		c.format = format
+	}
+}
+
+// NewClient create Client with options
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:      apiURL,
+		httpClient:   http.DefaultClient,
+		limiter:      NewRateLimiter(defaultRate, defaultBurst),
+		format:       FormatXML,
+		cache:        newCacheState(),
+		batchWorkers: defaultBatchWorkers,
+		logger:       slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+var defaultClient = NewClient()
+
+// endpoint return full url of api method path at baseURL with query
+func endpoint(baseURL, path string, q url.Values) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + path
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// get request api method path and decode response into v
+func (c *Client) get(ctx context.Context, path string, q url.Values, v interface{}) error {
+	if q.Get("format") == "" {
+		q.Set("format", string(c.format))
+	}
+	format := Format(q.Get("format"))
+	call := &callInfo{start: time.Now()}
+	c.onRequest(ctx, path, q)
+	body, err := c.cachedBody(ctx, call, path, q)
+	if err == nil {
+		err = decode(format, body, v)
+	}
+	c.onResponse(ctx, call, path, q, err)
+	return err
+}
+
+// getBody request api method path and return raw response body, failed
+// requests are repeated by RetryPolicy of Client
+func (c *Client) getBody(ctx context.Context, call *callInfo, path string, q url.Values) ([]byte, error) {
+	rawURL, err := endpoint(c.baseURL, path, q)
+	if err != nil {
+		return []byte{}, err
+	}
+	return c.withRetry(ctx, path, func() ([]byte, error) {
+		return c.doRequest(ctx, call, path, rawURL, q, nil)
+	})
+}
+
+// post send form in body of POST request to api method path at baseURL and
+// decode response into v. Responses of post are not cached and POST is not
+// repeated by RetryPolicy, so credentials are sent once
+func (c *Client) post(ctx context.Context, baseURL, path string, q, form url.Values, v interface{}) error {
+	if q.Get("format") == "" {
+		q.Set("format", string(c.format))
+	}
+	call := &callInfo{start: time.Now()}
+	c.onRequest(ctx, path, q)
+	rawURL, err := endpoint(baseURL, path, q)
+	if err == nil {
+		var body []byte
+		body, err = unwrapRetry(c.doRequest(ctx, call, path, rawURL, q, form))
+		if err == nil {
+			err = decode(Format(q.Get("format")), body, v)
+		}
+	}
+	c.onResponse(ctx, call, path, q, err)
+	return err
+}
+
+// doRequest make one request to api server, GET if form is nil, else POST
+// with form in body
+func (c *Client) doRequest(ctx context.Context, call *callInfo, path, rawURL string, q, form url.Values) ([]byte, error) {
+	if c.limiter != nil {
+		err := c.limiter.Wait(ctx, limitKey(q))
+		if err != nil {
+			return []byte{}, err
+		}
+	}
+	redacted := redactURL(rawURL)
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "cinemate request",
+		slog.String("endpoint", path), slog.String("url", redacted))
+	start := time.Now()
+	method, reqBody := "GET", io.Reader(nil)
+	if form != nil {
+		method, reqBody = "POST", strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reqBody)
+	if err != nil {
+		return []byte{}, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redacted
+		}
+		c.logFailure(ctx, path, 0, start, err)
+		return []byte{}, err
+	}
+	defer resp.Body.Close()
+	call.status = resp.StatusCode
+	body, err := ioutil.ReadAll(resp.Body)
+	call.bytes = len(body)
+	if err != nil {
+		c.logFailure(ctx, path, resp.StatusCode, start, err)
+		return []byte{}, err
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "cinemate response",
+		slog.String("endpoint", path), slog.Int("status", resp.StatusCode),
+		slog.Int("bytes", len(body)), slog.Duration("duration", time.Since(start)))
+	err = checkResponse(resp, Format(q.Get("format")), body)
+	if err != nil {
+		c.logFailure(ctx, path, resp.StatusCode, start, err)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			err = &retryError{err: err, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		}
+		return []byte{}, err
+	}
+	return body, nil
+}
+
+// checkResponse return error if response is error document or has not 200
+// status code
+func checkResponse(resp *http.Response, format Format, body []byte) error {
+	if apiErr := parseAPIError(format, body); apiErr != nil {
+		if apiErr.Code == 0 && resp.StatusCode != 200 {
+			apiErr.Code = int64(resp.StatusCode)
+		}
+		return apiErr
+	}
+	if resp.StatusCode == 429 {
+		return fmt.Errorf("Status Code %d received from cinemate.cc: %w", resp.StatusCode, ErrRateLimited)
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Status Code %d received from cinemate.cc", resp.StatusCode)
+	}
+	return nil
+}
+
+// limitKey return key of rate limit bucket for request query
+func limitKey(q url.Values) string {
+	if key := q.Get("apikey"); key != "" {
+		return key
+	}
+	return q.Get("passkey")
+}
+
+// decode unmarshal body in format into v
+func decode(format Format, body []byte, v interface{}) error {
+	if format == FormatJSON {
+		return json.Unmarshal(body, v)
+	}
+	return xml.Unmarshal(body, v)
+}
@@ -0,0 +1,199 @@
+package cinemate_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/serbe/cinemate"
+	"github.com/serbe/cinemate/cinematetest"
+)
+
+// seedClients return clients of seeded server in every format
+func seedClients(t *testing.T) map[cinemate.Format]*cinemate.Client {
+	t.Helper()
+	srv := cinematetest.NewServer(cinematetest.Seed())
+	t.Cleanup(srv.Close)
+	return map[cinemate.Format]*cinemate.Client{
+		cinemate.FormatXML:  srv.Client(cinemate.WithFormat(cinemate.FormatXML)),
+		cinemate.FormatJSON: srv.Client(cinemate.WithFormat(cinemate.FormatJSON)),
+	}
+}
+
+func TestClientMovies(t *testing.T) {
+	for format, c := range seedClients(t) {
+		t.Run(string(format), func(t *testing.T) {
+			movie, err := c.GetMovie(68675)
+			if err != nil || movie.TitleOriginal != "Henry's Crime" || movie.Year != 2010 {
+				t.Errorf("GetMovie() = %+v, %v", movie, err)
+			}
+			if movie.ReleaseDateRussia.Raw != "2011-04-14" {
+				t.Errorf("ReleaseDateRussia = %+v", movie.ReleaseDateRussia)
+			}
+			if _, err := c.GetMovie(1); !errors.Is(err, cinemate.ErrNotFound) {
+				t.Errorf("GetMovie(1) err = %v, want ErrNotFound", err)
+			}
+			movies, err := c.GetMovieList(cinemate.CCRequest{Year: 1994})
+			if err != nil || len(movies) != 1 || movies[0].ID != 2 {
+				t.Errorf("GetMovieList() = %+v, %v", movies, err)
+			}
+			movies, err = c.GetMovieSearch("чтиво 1994")
+			if err != nil || len(movies) != 1 || movies[0].ID != 2 {
+				t.Errorf("GetMovieSearch() = %+v, %v", movies, err)
+			}
+			movies, err = c.GetMovieSearch("чтиво 2010")
+			if err != nil || movies == nil || len(movies) != 0 {
+				t.Errorf("GetMovieSearch() = %#v, %v, want empty", movies, err)
+			}
+		})
+	}
+}
+
+func TestClientPersons(t *testing.T) {
+	for format, c := range seedClients(t) {
+		t.Run(string(format), func(t *testing.T) {
+			person, err := c.GetPerson(3971)
+			if err != nil || person.Name != "Квентин Тарантино" {
+				t.Errorf("GetPerson() = %+v, %v", person, err)
+			}
+			if _, err := c.GetPerson(1); !errors.Is(err, cinemate.ErrNotFound) {
+				t.Errorf("GetPerson(1) err = %v, want ErrNotFound", err)
+			}
+			persons, err := c.GetPersonMovies(3971)
+			if err != nil || len(persons) != 1 {
+				t.Errorf("GetPersonMovies() = %+v, %v", persons, err)
+			}
+			persons, err = c.GetPersonSearch("джилленхол")
+			if err != nil || len(persons) != 1 || persons[0].ID != 68675 {
+				t.Errorf("GetPersonSearch() = %+v, %v", persons, err)
+			}
+		})
+	}
+}
+
+func TestClientAccount(t *testing.T) {
+	ctx := context.Background()
+	for format, c := range seedClients(t) {
+		t.Run(string(format), func(t *testing.T) {
+			if _, err := c.Login(ctx, "user", "wrong"); !errors.Is(err, cinemate.ErrAuthFailed) {
+				t.Errorf("Login() err = %v, want ErrAuthFailed", err)
+			}
+			acc, err := c.Login(ctx, "user", "password")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if acc.Passkey != cinematetest.SeedPasskey {
+				t.Errorf("Passkey = %q", acc.Passkey)
+			}
+			profile, err := acc.GetAccountProfile()
+			if err != nil || profile.Username != "user" || profile.Reputation != 10 {
+				t.Errorf("GetAccountProfile() = %+v, %v", profile, err)
+			}
+			list, err := acc.GetAccountUpdateList()
+			if err != nil || list.Count != 1 || len(list.Items) != 1 || list.Items[0].URL != "http://cinemate.cc/movie/68675/" {
+				t.Errorf("GetAccountUpdateList() = %+v, %v", list, err)
+			}
+			if _, err := acc.GetAccountWatchlist(); err != nil {
+				t.Errorf("GetAccountWatchlist() err = %v", err)
+			}
+			stats, err := c.GetStatsNew()
+			if err != nil || stats.UsersCount != 4 || stats.MoviesCount != 1 {
+				t.Errorf("GetStatsNew() = %+v, %v", stats, err)
+			}
+		})
+	}
+}
+
+func TestClientInvalidKeys(t *testing.T) {
+	srv := cinematetest.NewServer(cinematetest.Seed())
+	defer srv.Close()
+	for _, format := range []cinemate.Format{cinemate.FormatXML, cinemate.FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			c := srv.Client(cinemate.WithFormat(format), cinemate.WithAPIKey("KEY"), cinemate.WithPasskey("PASSKEY"))
+			if _, err := c.GetMovie(2); !errors.Is(err, cinemate.ErrInvalidAPIKey) {
+				t.Errorf("GetMovie() err = %v, want ErrInvalidAPIKey", err)
+			}
+			if _, err := c.GetAccountProfile(); !errors.Is(err, cinemate.ErrInvalidPasskey) {
+				t.Errorf("GetAccountProfile() err = %v, want ErrInvalidPasskey", err)
+			}
+		})
+	}
+}
+
+func TestLoginNotRetried(t *testing.T) {
+	var posts, gets int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			atomic.AddInt32(&posts, 1)
+		} else {
+			atomic.AddInt32(&gets, 1)
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c := cinemate.NewClient(
+		cinemate.WithBaseURL(srv.URL),
+		cinemate.WithRateLimiter(nil),
+		cinemate.WithRetry(cinemate.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+	)
+	if _, err := c.Login(context.Background(), "user", "password"); err == nil {
+		t.Error("Login() err = nil, want error")
+	}
+	if n := atomic.LoadInt32(&posts); n != 1 {
+		t.Errorf("Login() sent %d POST requests, want 1", n)
+	}
+	if _, err := c.GetMovie(2); err == nil {
+		t.Error("GetMovie() err = nil, want error")
+	}
+	if n := atomic.LoadInt32(&gets); n != 3 {
+		t.Errorf("GetMovie() sent %d GET requests, want 3", n)
+	}
+}
+
+func TestLoginErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		authFailed bool
+		want       error
+	}{
+		{"wrong password", http.StatusUnauthorized, `<error><error>Invalid username or password</error><code>401</code></error>`, true, nil},
+		{"rejected without code", http.StatusOK, `<error>Неверный логин или пароль</error>`, true, nil},
+		{"malformed passkey", http.StatusOK, `<response><passkey>PASSKEY</passkey></response>`, true, nil},
+		{"rate limited", http.StatusTooManyRequests, `<error><error>Rate limit exceeded</error><code>429</code></error>`, false, cinemate.ErrRateLimited},
+		{"server error", http.StatusInternalServerError, `<error><error>Internal error</error><code>500</code></error>`, false, nil},
+		{"unavailable", http.StatusServiceUnavailable, `unavailable`, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+			c := cinemate.NewClient(cinemate.WithBaseURL(srv.URL), cinemate.WithRateLimiter(nil))
+			_, err := c.Login(context.Background(), "user", "password")
+			if err == nil {
+				t.Fatal("Login() err = nil")
+			}
+			var authErr *cinemate.AuthError
+			if got := errors.As(err, &authErr); got != tt.authFailed || errors.Is(err, cinemate.ErrAuthFailed) != tt.authFailed {
+				t.Errorf("Login() err = %v, want AuthError %v", err, tt.authFailed)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Login() err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	_, err := cinemate.NewClient(cinemate.WithBaseURL(srv.URL), cinemate.WithRateLimiter(nil)).Login(context.Background(), "user", "password")
+	if err == nil || errors.Is(err, cinemate.ErrAuthFailed) {
+		t.Errorf("Login() of transport error err = %v, want not ErrAuthFailed", err)
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/serbe/cinemate"
+)
+
+// app run commands of cli
+type app struct {
+	cfg         Config
+	out         *output
+	client      *cinemate.Client
+	capture     *captureTransport
+	prompt      *prompter
+	credentials string
+	profileName string
+}
+
+func newApp(cfg Config, out *output, extra ...cinemate.Option) *app {
+	capture := &captureTransport{next: http.DefaultTransport}
+	opts := []cinemate.Option{
+		cinemate.WithHTTPClient(&http.Client{Transport: capture, Timeout: 30 * time.Second}),
+		cinemate.WithUserAgent("cinemate-cli"),
+		cinemate.WithAPIKey(cfg.APIKey),
+		cinemate.WithPasskey(cfg.Passkey),
+	}
+	if cfg.BaseURL != "" {
+		opts = append(opts, cinemate.WithBaseURL(cfg.BaseURL))
+	}
+	opts = append(opts, extra...)
+	return &app{
+		cfg:     cfg,
+		out:     out,
+		client:  cinemate.NewClient(opts...),
+		capture: capture,
+		prompt:  newPrompter(os.Stdin, os.Stderr),
+	}
+}
+
+func (a *app) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("command required, see cinemate -h")
+	}
+	cmd, args := args[0], args[1:]
+	if cmd == "stats" {
+		return a.stats(ctx)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("%s: subcommand required", cmd)
+	}
+	sub, args := args[0], args[1:]
+	if cmd == "profile" {
+		return a.profile(sub, args)
+	}
+	switch cmd + " " + sub {
+	case "movie get":
+		return a.movieGet(ctx, args)
+	case "movie list":
+		return a.movieList(ctx, args)
+	case "movie search":
+		return a.movieSearch(ctx, args)
+	case "person get":
+		return a.personGet(ctx, args)
+	case "person movies":
+		return a.personMovies(ctx, args)
+	case "person search":
+		return a.personSearch(ctx, args)
+	case "account login":
+		return a.accountLogin(ctx, args)
+	case "account profile":
+		return a.accountProfile(ctx)
+	case "account updates":
+		return a.accountUpdates(ctx, args)
+	case "account watchlist":
+		return a.accountWatchlist(ctx)
+	}
+	return fmt.Errorf("unknown command %q", cmd+" "+sub)
+}
+
+func (a *app) requireAPIKey() error {
+	if a.cfg.APIKey == "" {
+		return errors.New("api key required: set -apikey, CINEMATE_APIKEY, -profile or config file")
+	}
+	return nil
+}
+
+func (a *app) requirePasskey() error {
+	if a.cfg.Passkey == "" {
+		return errors.New("passkey required: set -passkey, CINEMATE_PASSKEY, -profile or config file")
+	}
+	return nil
+}
+
+func (a *app) movieGet(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+	if err := a.requireAPIKey(); err != nil {
+		return err
+	}
+	movie, err := a.client.GetMovieContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.out.print(movie, a.capture.body(), []string{"FIELD", "VALUE"}, [][]string{
+		{"ID", strconv.FormatInt(movie.ID, 10)},
+		{"Название", movie.TitleRussian},
+		{"Оригинал", movie.TitleOriginal},
+		{"Год", strconv.FormatInt(movie.Year, 10)},
+		{"Длительность", strconv.FormatInt(movie.Runtime, 10)},
+		{"Страна", strings.Join(movie.Country.Name, ", ")},
+		{"Жанр", strings.Join(movie.Genre.Name, ", ")},
+		{"Режиссер", movie.Director.Name},
+		{"IMDb", formatRating(movie.Imdb.Rating, movie.Imdb.Votes)},
+		{"Кинопоиск", formatRating(movie.Kinopoisk.Rating, movie.Kinopoisk.Votes)},
+		{"Выход в мире", movie.ReleaseDateWorld.Raw},
+		{"Выход в России", movie.ReleaseDateRussia.Raw},
+		{"URL", movie.URL},
+	})
+}
+
+func (a *app) movieList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("movie list", flag.ContinueOnError)
+	var (
+		ccr      cinemate.CCRequest
+		from, to string
+		all      bool
+		max      int
+	)
+	fs.Func("type", "тип фильмов: movie, serial, short", func(s string) error { ccr.Type = cinemate.MovieType(s); return nil })
+	fs.Func("state", "состояние фильма: soon, cinema", func(s string) error { ccr.State = cinemate.State(s); return nil })
+	fs.Func("mode", "режим: best", func(s string) error { ccr.Mode = cinemate.Mode(s); return nil })
+	fs.Int64Var(&ccr.Year, "year", 0, "год выпуска")
+	fs.StringVar(&ccr.Genre, "genre", "", "жанр (slug)")
+	fs.StringVar(&ccr.Country, "country", "", "страна (slug)")
+	fs.Func("order-by", "сортировка: create_date, release_date, ru_release_date", func(s string) error { ccr.OrderBy = cinemate.OrderBy(s); return nil })
+	fs.Func("order", "порядок: desc, asc", func(s string) error { ccr.Order = cinemate.Order(s); return nil })
+	fs.StringVar(&from, "from", "", "начало среза order-by, ДД.ММ.ГГГГ")
+	fs.StringVar(&to, "to", "", "конец среза order-by, ДД.ММ.ГГГГ")
+	fs.Int64Var(&ccr.Page, "page", 0, "страница")
+	fs.Int64Var(&ccr.PerPage, "per-page", 0, "записей на странице, не более 25")
+	fs.BoolVar(&all, "all", false, "все страницы, несовместимо с -o xml")
+	fs.IntVar(&max, "max", 0, "максимальное число фильмов для -all")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if all && a.out.format == "xml" {
+		return errors.New("-all is not supported with -o xml")
+	}
+	var err error
+	if ccr.From, err = parseDate(from); err != nil {
+		return err
+	}
+	if ccr.To, err = parseDate(to); err != nil {
+		return err
+	}
+	if err := ccr.Validate(); err != nil {
+		return err
+	}
+	if err := a.requireAPIKey(); err != nil {
+		return err
+	}
+	var movies []cinemate.Movie
+	if all {
+		for movie, err := range a.client.MovieListAll(ctx, ccr, max) {
+			if err != nil {
+				return err
+			}
+			movies = append(movies, movie)
+		}
+	} else {
+		movies, err = a.client.GetMovieListContext(ctx, ccr)
+		if err != nil {
+			return err
+		}
+	}
+	return a.printMovies(movies)
+}
+
+func (a *app) movieSearch(ctx context.Context, args []string) error {
+	term := strings.Join(args, " ")
+	if term == "" {
+		return errors.New("search term required")
+	}
+	if err := a.requireAPIKey(); err != nil {
+		return err
+	}
+	movies, err := a.client.GetMovieSearchContext(ctx, term)
+	if err != nil {
+		return err
+	}
+	return a.printMovies(movies)
+}
+
+func (a *app) printMovies(movies []cinemate.Movie) error {
+	rows := make([][]string, 0, len(movies))
+	for _, m := range movies {
+		rows = append(rows, []string{
+			strconv.FormatInt(m.ID, 10),
+			strconv.FormatInt(m.Year, 10),
+			m.TitleRussian,
+			m.TitleOriginal,
+			formatRating(m.Imdb.Rating, m.Imdb.Votes),
+		})
+	}
+	return a.out.print(movies, a.capture.body(), []string{"ID", "YEAR", "TITLE", "ORIGINAL", "IMDB"}, rows)
+}
+
+func (a *app) personGet(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+	if err := a.requireAPIKey(); err != nil {
+		return err
+	}
+	person, err := a.client.GetPersonContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.out.print(person, a.capture.body(), []string{"FIELD", "VALUE"}, [][]string{
+		{"ID", strconv.FormatInt(person.ID, 10)},
+		{"Имя", person.Name},
+		{"Оригинал", person.NameOriginal},
+		{"URL", person.URL},
+	})
+}
+
+func (a *app) personMovies(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+	if err := a.requireAPIKey(); err != nil {
+		return err
+	}
+	persons, err := a.client.GetPersonMoviesContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, p := range persons {
+		for _, m := range p.Movies.Director {
+			rows = append(rows, []string{"режиссер", strconv.FormatInt(m.ID, 10), strconv.FormatInt(m.Year, 10), m.TitleRussian})
+		}
+		for _, m := range p.Movies.Actor {
+			rows = append(rows, []string{"актер", strconv.FormatInt(m.ID, 10), strconv.FormatInt(m.Year, 10), m.TitleRussian})
+		}
+	}
+	return a.out.print(persons, a.capture.body(), []string{"ROLE", "ID", "YEAR", "TITLE"}, rows)
+}
+
+func (a *app) personSearch(ctx context.Context, args []string) error {
+	term := strings.Join(args, " ")
+	if term == "" {
+		return errors.New("search term required")
+	}
+	if err := a.requireAPIKey(); err != nil {
+		return err
+	}
+	persons, err := a.client.GetPersonSearchContext(ctx, term)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(persons))
+	for _, p := range persons {
+		rows = append(rows, []string{strconv.FormatInt(p.ID, 10), p.Name, p.NameOriginal})
+	}
+	return a.out.print(persons, a.capture.body(), []string{"ID", "NAME", "ORIGINAL"}, rows)
+}
+
+func (a *app) accountLogin(ctx context.Context, args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errors.New("usage: account login USERNAME [PASSWORD]")
+	}
+	if len(args) == 1 {
+		password, err := a.prompt.ask("password: ")
+		if err != nil {
+			return err
+		}
+		args = append(args, password)
+	}
+	acc, err := a.client.Login(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	if err := a.saveLogin(args[0], acc.Passkey); err != nil {
+		return err
+	}
+	return a.out.print(struct {
+		Passkey string `json:"passkey"`
+	}{acc.Passkey}, a.capture.body(), nil, [][]string{{acc.Passkey}})
+}
+
+func (a *app) accountProfile(ctx context.Context) error {
+	if err := a.requirePasskey(); err != nil {
+		return err
+	}
+	p, err := a.client.GetAccountProfileContext(ctx)
+	if err != nil {
+		return err
+	}
+	return a.out.print(p, a.capture.body(), []string{"FIELD", "VALUE"}, [][]string{
+		{"Логин", p.Username},
+		{"Репутация", strconv.FormatInt(p.Reputation, 10)},
+		{"Отзывы", strconv.FormatInt(p.ReviewCount, 10)},
+		{"Награды", fmt.Sprintf("%d/%d/%d", p.GoldBadges, p.SilverBadges, p.BronzeBadges)},
+		{"Личные сообщения", strconv.FormatInt(p.UnreadPmCount, 10)},
+		{"Форум", strconv.FormatInt(p.UnreadForumCount, 10)},
+		{"Лента обновлений", strconv.FormatInt(p.UnreadUpdatelistCount, 10)},
+		{"Подписки", strconv.FormatInt(p.SubscriptionCount, 10)},
+	})
+}
+
+func (a *app) accountUpdates(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("account updates", flag.ContinueOnError)
+	all := fs.Bool("all", false, "все записи, а не только непрочитанные")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := a.requirePasskey(); err != nil {
+		return err
+	}
+	list, err := a.client.GetAccountUpdateListContext(ctx, !*all)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		rows = append(rows, []string{item.Date.Raw, strconv.FormatInt(item.New, 10), item.Description, item.URL})
+	}
+	return a.out.print(list, a.capture.body(), []string{"DATE", "NEW", "DESCRIPTION", "URL"}, rows)
+}
+
+func (a *app) accountWatchlist(ctx context.Context) error {
+	if err := a.requirePasskey(); err != nil {
+		return err
+	}
+	list, err := a.client.GetAccountWatchlistContext(ctx)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, group := range []struct {
+		kind    string
+		objects []cinemate.WatchListObject
+	}{{"movie", list.Movies}, {"person", list.Persons}, {"comment", list.Comments}} {
+		for _, o := range group.objects {
+			rows = append(rows, []string{group.kind, o.Date.Raw, o.Name, o.URL})
+		}
+	}
+	return a.out.print(list, a.capture.body(), []string{"TYPE", "DATE", "NAME", "URL"}, rows)
+}
+
+func (a *app) stats(ctx context.Context) error {
+	s, err := a.client.GetStatsNewContext(ctx)
+	if err != nil {
+		return err
+	}
+	return a.out.print(s, a.capture.body(), []string{"FIELD", "VALUE"}, [][]string{
+		{"Пользователи", strconv.FormatInt(s.UsersCount, 10)},
+		{"Отзывы", strconv.FormatInt(s.ReviewsCount, 10)},
+		{"Комментарии", strconv.FormatInt(s.CommentsCount, 10)},
+		{"Фильмы", strconv.FormatInt(s.MoviesCount, 10)},
+	})
+}
+
+func parseID(args []string) (int64, error) {
+	if len(args) != 1 {
+		return 0, errors.New("ID required")
+	}
+	return strconv.ParseInt(args[0], 10, 64)
+}
+
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("02.01.2006", s, cinemate.Moscow)
+}
+
+func formatRating(rating float64, votes int64) string {
+	if votes == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f (%d)", rating, votes)
+}
+
+// captureTransport keep raw body of last successful response for xml output.
+// Failed responses, retried by client, are not kept
+type captureTransport struct {
+	next http.RoundTripper
+	mu   sync.Mutex
+	last []byte
+}
+
+func (t *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		t.mu.Lock()
+		t.last = body
+		t.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// body return raw body of last successful response
+func (t *captureTransport) body() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Config credentials and server of cli
+// apikey   ключ разработчика
+// passkey  PASSKEY пользователя
+// base_url адрес api сервера
+type Config struct {
+	APIKey  string `json:"apikey"`
+	Passkey string `json:"passkey"`
+	BaseURL string `json:"base_url"`
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cinemate", "config.json")
+}
+
+// loadConfig read config file, absent file is not error, and override it by
+// environment variables
+func loadConfig(path string) (Config, error) {
+	var cfg Config
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				return cfg, err
+			}
+		case !os.IsNotExist(err):
+			return cfg, err
+		}
+	}
+	cfg.override(Config{
+		APIKey:  os.Getenv("CINEMATE_APIKEY"),
+		Passkey: os.Getenv("CINEMATE_PASSKEY"),
+		BaseURL: os.Getenv("CINEMATE_BASE_URL"),
+	})
+	return cfg, nil
+}
+
+// override set not empty values of o
+func (cfg *Config) override(o Config) {
+	if o.APIKey != "" {
+		cfg.APIKey = o.APIKey
+	}
+	if o.Passkey != "" {
+		cfg.Passkey = o.Passkey
+	}
+	if o.BaseURL != "" {
+		cfg.BaseURL = o.BaseURL
+	}
+}
@@ -0,0 +1,112 @@
+// Command cinemate is command-line client of api.cinemate.cc.
+//
+// Usage:
+//
+//	cinemate [flags] movie get ID
+//	cinemate [flags] movie list [list flags]
+//	cinemate [flags] movie search TERM
+//	cinemate [flags] person get ID
+//	cinemate [flags] person movies ID
+//	cinemate [flags] person search TERM
+//	cinemate [flags] account login USERNAME [PASSWORD]
+//	cinemate [flags] account profile
+//	cinemate [flags] account updates [-all]
+//	cinemate [flags] account watchlist
+//	cinemate [flags] stats
+//	cinemate [flags] profile list
+//	cinemate [flags] profile set NAME
+//	cinemate [flags] profile delete NAME
+//
+// Credentials are read from flags, environment variables CINEMATE_APIKEY,
+// CINEMATE_PASSKEY, CINEMATE_BASE_URL, profile of encrypted credential store
+// selected by -profile or CINEMATE_PROFILE, or config file
+// $HOME/.config/cinemate/config.json. Passphrase of credential store is read
+// from CINEMATE_PASSPHRASE or asked on terminal. Secrets asked on terminal
+// do not get into shell history.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/serbe/cinemate"
+	"github.com/serbe/cinemate/credstore"
+)
+
+const usage = `usage: cinemate [flags] command [args]
+
+commands:
+  movie get ID                   информация о фильме
+  movie list [flags]             список фильмов
+  movie search TERM              поиск фильмов
+  person get ID                  информация о персоне
+  person movies ID               фильмы персоны
+  person search TERM             поиск персон
+  account login USER [PASSWORD]  получить passkey, сохранить в -profile
+  account profile                профиль пользователя
+  account updates [-all]         лента обновлений
+  account watchlist              список слежения
+  stats                          статистика сайта за сутки
+  profile list                   профили хранилища учетных данных
+  profile set NAME               сохранить профиль, значения запрашиваются
+  profile delete NAME            удалить профиль
+
+flags:
+`
+
+func main() {
+	var (
+		cfgPath  = flag.String("config", defaultConfigPath(), "config file")
+		output   = flag.String("o", "table", "output format: table, json or xml")
+		apiKey   = flag.String("apikey", "", "api key")
+		passkey  = flag.String("passkey", "", "passkey")
+		baseURL  = flag.String("base-url", "", "base url of api server")
+		verbose  = flag.Bool("v", false, "log requests to stderr")
+		profile  = flag.String("profile", os.Getenv(credstore.EnvProfile), "profile of credential store")
+		credPath = flag.String("credentials", credstore.DefaultPath(), "encrypted credential store")
+	)
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	cfg, err := loadConfig(*cfgPath)
+	if err != nil {
+		fatal(err)
+	}
+	prompt := newPrompter(os.Stdin, os.Stderr)
+	if *profile != "" && flag.Arg(0) != "profile" {
+		p, err := loadProfile(*credPath, *profile, prompt)
+		if err != nil && !errors.Is(err, credstore.ErrNoProfile) {
+			fatal(err)
+		}
+		cfg.override(p)
+	}
+	cfg.override(Config{APIKey: *apiKey, Passkey: *passkey, BaseURL: *baseURL})
+	out, err := newOutput(*output, os.Stdout)
+	if err != nil {
+		fatal(err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var opts []cinemate.Option
+	if *verbose {
+		opts = append(opts, cinemate.WithLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	}
+	app := newApp(cfg, out, opts...)
+	app.prompt, app.credentials, app.profileName = prompt, *credPath, *profile
+	if err := app.run(ctx, flag.Args()); err != nil {
+		stop()
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "cinemate:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// output print results of commands in table, json or raw xml of server
+type output struct {
+	format string
+	w      io.Writer
+}
+
+func newOutput(format string, w io.Writer) (*output, error) {
+	switch format {
+	case "table", "json", "xml":
+		return &output{format: format, w: w}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+// print write v as json or rows as table, in xml format raw is written
+func (o *output) print(v interface{}, raw []byte, header []string, rows [][]string) error {
+	switch o.format {
+	case "json":
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(v)
+	case "xml":
+		_, err := o.w.Write(raw)
+		if err == nil && (len(raw) == 0 || raw[len(raw)-1] != '\n') {
+			_, err = io.WriteString(o.w, "\n")
+		}
+		return err
+	}
+	tw := tabwriter.NewWriter(o.w, 0, 4, 2, ' ', 0)
+	if len(header) > 0 {
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
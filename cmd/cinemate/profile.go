@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/serbe/cinemate"
+	"github.com/serbe/cinemate/credstore"
+)
+
+// prompter ask values which should not be passed in arguments of command
+type prompter struct {
+	in         *bufio.Reader
+	out        io.Writer
+	passphrase string
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// ask write question and read one line of answer
+func (p *prompter) ask(question string) (string, error) {
+	fmt.Fprint(p.out, question)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// openStore open credential store at path with passphrase from
+// CINEMATE_PASSPHRASE or asked by prompter
+func openStore(path string, p *prompter) (*credstore.Store, error) {
+	if path == "" {
+		return nil, errors.New("credentials file unknown: set -credentials")
+	}
+	if p.passphrase == "" {
+		p.passphrase = os.Getenv(credstore.EnvPassphrase)
+	}
+	if p.passphrase == "" {
+		passphrase, err := p.ask("passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		p.passphrase = passphrase
+	}
+	return credstore.Open(path, p.passphrase)
+}
+
+// loadProfile return credentials of profile overridden by environment
+func loadProfile(path, name string, p *prompter) (Config, error) {
+	store, err := openStore(path, p)
+	if err != nil {
+		return Config{}, err
+	}
+	profile, err := store.Load(name)
+	if err != nil {
+		return Config{}, err
+	}
+	return Config{APIKey: profile.APIKey, Passkey: profile.Passkey}, nil
+}
+
+func (a *app) profile(sub string, args []string) error {
+	switch sub {
+	case "list":
+		return a.profileList()
+	case "set":
+		return a.profileSet(args)
+	case "delete":
+		return a.profileDelete(args)
+	}
+	return fmt.Errorf("unknown command %q", "profile "+sub)
+}
+
+func (a *app) profileList() error {
+	store, err := openStore(a.credentials, a.prompt)
+	if err != nil {
+		return err
+	}
+	names := store.Names()
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		p, _ := store.Get(name)
+		rows = append(rows, []string{name, p.Username})
+	}
+	return a.out.print(names, nil, []string{"PROFILE", "USERNAME"}, rows)
+}
+
+func (a *app) profileSet(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: profile set NAME")
+	}
+	store, err := openStore(a.credentials, a.prompt)
+	if err != nil {
+		return err
+	}
+	p, _ := store.Get(args[0])
+	for _, f := range []struct {
+		question string
+		value    *string
+	}{
+		{"api key", &p.APIKey},
+		{"username", &p.Username},
+		{"passkey", &p.Passkey},
+	} {
+		answer, err := a.prompt.ask(f.question + " (empty keep current): ")
+		if err != nil {
+			return err
+		}
+		if answer != "" {
+			*f.value = answer
+		}
+	}
+	if p.Passkey != "" && !cinemate.ValidPasskey(p.Passkey) {
+		return errors.New("passkey must be 40 hex digits")
+	}
+	store.Set(args[0], p)
+	return store.Save()
+}
+
+func (a *app) profileDelete(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: profile delete NAME")
+	}
+	store, err := openStore(a.credentials, a.prompt)
+	if err != nil {
+		return err
+	}
+	if _, err := store.Get(args[0]); err != nil {
+		return err
+	}
+	store.Delete(args[0])
+	return store.Save()
+}
+
+// saveLogin save username and passkey of login to profile of app
+func (a *app) saveLogin(username, passkey string) error {
+	if a.profileName == "" {
+		return nil
+	}
+	store, err := openStore(a.credentials, a.prompt)
+	if err != nil {
+		return err
+	}
+	p, _ := store.Get(a.profileName)
+	p.Username, p.Passkey = username, passkey
+	store.Set(a.profileName, p)
+	return store.Save()
+}
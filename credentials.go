@@ -0,0 +1,119 @@
+package cinemate
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// CredentialsProvider return login and password of user for repeated
+// authorization when PASSKEY is rejected by server
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (username string, password string, err error)
+}
+
+// CredentialsFunc is function implementing CredentialsProvider
+type CredentialsFunc func(ctx context.Context) (username string, password string, err error)
+
+// Credentials implement CredentialsProvider
+func (f CredentialsFunc) Credentials(ctx context.Context) (username string, password string, err error) {
+	return f(ctx)
+}
+
+// StaticCredentials return CredentialsProvider with fixed login and password
+func StaticCredentials(username string, password string) CredentialsProvider {
+	return CredentialsFunc(func(context.Context) (string, string, error) {
+		return username, password, nil
+	})
+}
+
+// reauth current passkey of Account with CredentialsProvider, shared by all
+// goroutines using Account
+type reauth struct {
+	provider CredentialsProvider
+	mu       sync.RWMutex
+	passkey  string
+	login    sync.Mutex
+}
+
+// AccountWithCredentials return Account using Client, passkey of Client and
+// provider. When server reject passkey, Account once authorize again by
+// Login with credentials from provider, replace passkey for all goroutines
+// and repeat request. Without passkey of Client Account authorize on first
+// request
+func (c *Client) AccountWithCredentials(provider CredentialsProvider) *Account {
+	return &Account{
+		Passkey: c.passkey,
+		client:  c,
+		reauth:  &reauth{provider: provider, passkey: c.passkey},
+	}
+}
+
+// CurrentPasskey return passkey used by requests of Account, it differ from
+// Passkey after repeated authorization
+func (acc *Account) CurrentPasskey() string {
+	if acc.reauth == nil {
+		return acc.Passkey
+	}
+	return acc.reauth.current()
+}
+
+// withPasskey call f with current passkey of Account. If Account has
+// CredentialsProvider and f fail with ErrInvalidPasskey, f is called once
+// more with passkey of new authorization
+func (acc *Account) withPasskey(ctx context.Context, f func(passkey string) error) error {
+	if acc.reauth == nil {
+		return f(acc.Passkey)
+	}
+	passkey, err := acc.reauth.session(ctx, acc.getClient())
+	if err != nil {
+		return err
+	}
+	err = f(passkey)
+	if !errors.Is(err, ErrInvalidPasskey) {
+		return err
+	}
+	passkey, err = acc.reauth.refresh(ctx, acc.getClient(), passkey)
+	if err != nil {
+		return err
+	}
+	return f(passkey)
+}
+
+func (r *reauth) current() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.passkey
+}
+
+// session return current passkey, authorizing first when there is no
+// passkey yet. Absence of passkey is checked again by refresh under login
+// lock, so concurrent first requests authorize once
+func (r *reauth) session(ctx context.Context, c *Client) (string, error) {
+	if passkey := r.current(); passkey != "" {
+		return passkey, nil
+	}
+	return r.refresh(ctx, c, "")
+}
+
+// refresh authorize again and return new passkey. Goroutines with the same
+// stale passkey wait for one authorization and share its result
+func (r *reauth) refresh(ctx context.Context, c *Client, stale string) (string, error) {
+	r.login.Lock()
+	defer r.login.Unlock()
+	if passkey := r.current(); passkey != stale {
+		return passkey, nil
+	}
+	username, password, err := r.provider.Credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	acc, err := c.Login(ctx, username, password)
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	r.passkey = acc.Passkey
+	r.mu.Unlock()
+	return acc.Passkey, nil
+}
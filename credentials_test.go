@@ -0,0 +1,89 @@
+package cinemate_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/serbe/cinemate"
+	"github.com/serbe/cinemate/cinematetest"
+)
+
+// countingCredentials return credentials of seeded user and counter of calls
+func countingCredentials(password string) (cinemate.CredentialsProvider, *int32) {
+	var n int32
+	return cinemate.CredentialsFunc(func(context.Context) (string, string, error) {
+		atomic.AddInt32(&n, 1)
+		return "user", password, nil
+	}), &n
+}
+
+// profiles request profile from n goroutines and return first error
+func profiles(acc *cinemate.Account, n int) error {
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := acc.GetAccountProfile(); err != nil {
+				once.Do(func() { firstErr = err })
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func TestAccountWithCredentialsConcurrent(t *testing.T) {
+	srv := cinematetest.NewServer(cinematetest.Seed())
+	defer srv.Close()
+	provider, logins := countingCredentials("password")
+	acc := srv.Client().AccountWithCredentials(provider)
+	if err := profiles(acc, 20); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(logins); n != 1 {
+		t.Errorf("first requests logged in %d times, want 1", n)
+	}
+	if acc.CurrentPasskey() != cinematetest.SeedPasskey {
+		t.Errorf("CurrentPasskey() = %q", acc.CurrentPasskey())
+	}
+	rotated := "fedcba9876543210fedcba9876543210fedcba98"
+	srv.SetPasskey("user", rotated)
+	if err := profiles(acc, 20); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(logins); n != 2 {
+		t.Errorf("requests after rotation logged in %d times in total, want 2", n)
+	}
+	if acc.CurrentPasskey() != rotated {
+		t.Errorf("CurrentPasskey() = %q, want %q", acc.CurrentPasskey(), rotated)
+	}
+}
+
+func TestAccountWithCredentialsFailed(t *testing.T) {
+	srv := cinematetest.NewServer(cinematetest.Seed())
+	defer srv.Close()
+	provider, logins := countingCredentials("wrong")
+	acc := srv.Client(cinemate.WithPasskey(cinematetest.SeedPasskey)).AccountWithCredentials(provider)
+	if _, err := acc.GetAccountProfile(); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(logins); n != 0 {
+		t.Errorf("valid passkey logged in %d times, want 0", n)
+	}
+	srv.SetPasskey("user", "fedcba9876543210fedcba9876543210fedcba98")
+	if _, err := acc.GetAccountProfile(); !errors.Is(err, cinemate.ErrAuthFailed) {
+		t.Errorf("GetAccountProfile() err = %v, want ErrAuthFailed", err)
+	}
+	plain := srv.Client(cinemate.WithPasskey(cinematetest.SeedPasskey)).Account()
+	if _, err := plain.GetAccountProfile(); !errors.Is(err, cinemate.ErrInvalidPasskey) {
+		t.Errorf("Account without credentials err = %v, want ErrInvalidPasskey", err)
+	}
+}
@@ -0,0 +1,328 @@
+// Package credstore keep named profiles of cinemate credentials in a file
+// encrypted by passphrase. Key of file is derived from passphrase by
+// PBKDF2-SHA256, profiles are sealed by AES-256-GCM, so store need no OS
+// keychain.
+//
+//	store, err := credstore.Open(credstore.DefaultPath(), passphrase)
+//	store.Set("work", credstore.Profile{APIKey: "APIKEY", Passkey: "PASSKEY"})
+//	err = store.Save()
+//	api, err := store.Init("work")
+//
+// Values of profile are overridden by environment variables CINEMATE_APIKEY,
+// CINEMATE_USERNAME and CINEMATE_PASSKEY.
+package credstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/serbe/cinemate"
+)
+
+const (
+	// fileVersion версия формата файла
+	fileVersion = 1
+	// Iterations число итераций PBKDF2 для новых файлов
+	Iterations = 600000
+	// minIterations и maxIterations допустимое число итераций PBKDF2 в
+	// файле, поврежденный файл не должен замедлить Open на неограниченное время
+	minIterations = 10000
+	maxIterations = 10 * Iterations
+	saltSize      = 16
+	keySize       = 32
+)
+
+// Environment variables overriding values of profile
+const (
+	EnvAPIKey     = "CINEMATE_APIKEY"
+	EnvUsername   = "CINEMATE_USERNAME"
+	EnvPasskey    = "CINEMATE_PASSKEY"
+	EnvPassphrase = "CINEMATE_PASSPHRASE"
+	EnvProfile    = "CINEMATE_PROFILE"
+)
+
+// DefaultProfile имя профиля, если имя не задано и не задана переменная CINEMATE_PROFILE
+const DefaultProfile = "default"
+
+var (
+	// ErrNoProfile профиль отсутствует в хранилище
+	ErrNoProfile = errors.New("credstore: profile not found")
+	// ErrWrongPassphrase неверная парольная фраза или поврежденный файл
+	ErrWrongPassphrase = errors.New("credstore: wrong passphrase or corrupted file")
+)
+
+// Profile credentials of one profile
+// APIKey   ключ разработчика
+// Username логин пользователя
+// Passkey  PASSKEY пользователя
+type Profile struct {
+	APIKey   string `json:"apikey,omitempty"`
+	Username string `json:"username,omitempty"`
+	Passkey  string `json:"passkey,omitempty"`
+}
+
+// file encrypted file of store
+type file struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// Store profiles of credentials in encrypted file
+type Store struct {
+	path       string
+	passphrase string
+	mu         sync.Mutex
+	profiles   map[string]Profile
+}
+
+// DefaultPath return $HOME/.config/cinemate/credentials or empty string if
+// config directory is unknown
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cinemate", "credentials")
+}
+
+// Open read and decrypt store at path. Absent file is empty store, it is
+// created by Save
+func Open(path string, passphrase string) (*Store, error) {
+	s := &Store{path: path, passphrase: passphrase, profiles: make(map[string]Profile)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("credstore: %s: %w", path, err)
+	}
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("credstore: %s: unsupported version %d", path, f.Version)
+	}
+	if f.Iterations < minIterations || f.Iterations > maxIterations {
+		return nil, fmt.Errorf("credstore: %s: invalid number of iterations %d", path, f.Iterations)
+	}
+	aead, err := newAEAD(passphrase, f.Salt, f.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := aead.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if err := json.Unmarshal(plain, &s.profiles); err != nil {
+		return nil, fmt.Errorf("credstore: %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Names return sorted names of profiles
+func (s *Store) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.profiles))
+	for name := range s.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get return saved profile without environment overrides
+func (s *Store) Get(name string) (Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.profiles[name]
+	if !ok {
+		return p, fmt.Errorf("%w: %q", ErrNoProfile, name)
+	}
+	return p, nil
+}
+
+// Set add or replace profile, changes are written by Save
+func (s *Store) Set(name string, p Profile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles[name] = p
+}
+
+// Delete remove profile, changes are written by Save
+func (s *Store) Delete(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.profiles, name)
+}
+
+// Save encrypt profiles with new salt and nonce and atomically replace
+// file of store, readable only by owner
+func (s *Store) Save() error {
+	s.mu.Lock()
+	plain, err := json.Marshal(s.profiles)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	f := file{Version: fileVersion, Iterations: Iterations, Salt: make([]byte, saltSize)}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return err
+	}
+	aead, err := newAEAD(s.passphrase, f.Salt, f.Iterations)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = aead.Seal(nil, f.Nonce, plain, nil)
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(s.path, data)
+}
+
+// Load return profile with values overridden by environment variables.
+// Empty name is CINEMATE_PROFILE or DefaultProfile. Absent profile is not
+// error if environment set any value
+func (s *Store) Load(name string) (Profile, error) {
+	if name == "" {
+		name = os.Getenv(EnvProfile)
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+	p, err := s.Get(name)
+	env := Profile{
+		APIKey:   os.Getenv(EnvAPIKey),
+		Username: os.Getenv(EnvUsername),
+		Passkey:  os.Getenv(EnvPasskey),
+	}
+	if err != nil && env == (Profile{}) {
+		return p, err
+	}
+	p.override(env)
+	return p, nil
+}
+
+// Init return API with api key of profile, as cinemate.Init
+func (s *Store) Init(name string, opts ...cinemate.Option) (*cinemate.API, error) {
+	p, err := s.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("credstore: profile %q has no api key", name)
+	}
+	opts = append([]cinemate.Option{cinemate.WithAPIKey(p.APIKey)}, opts...)
+	return &cinemate.API{Client: cinemate.NewClient(opts...)}, nil
+}
+
+// InitAccount return Account with passkey of profile, as
+// cinemate.InitAccount
+func (s *Store) InitAccount(name string, opts ...cinemate.Option) (*cinemate.Account, error) {
+	p, err := s.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	if !cinemate.ValidPasskey(p.Passkey) {
+		return nil, fmt.Errorf("credstore: profile %q has no valid passkey", name)
+	}
+	opts = append([]cinemate.Option{cinemate.WithAPIKey(p.APIKey), cinemate.WithPasskey(p.Passkey)}, opts...)
+	return cinemate.NewClient(opts...).Account(), nil
+}
+
+// Init open store at DefaultPath with passphrase from CINEMATE_PASSPHRASE
+// and return API of profile
+func Init(name string, opts ...cinemate.Option) (*cinemate.API, error) {
+	s, err := Open(DefaultPath(), os.Getenv(EnvPassphrase))
+	if err != nil {
+		return nil, err
+	}
+	return s.Init(name, opts...)
+}
+
+// InitAccount open store at DefaultPath with passphrase from
+// CINEMATE_PASSPHRASE and return Account of profile
+func InitAccount(name string, opts ...cinemate.Option) (*cinemate.Account, error) {
+	s, err := Open(DefaultPath(), os.Getenv(EnvPassphrase))
+	if err != nil {
+		return nil, err
+	}
+	return s.InitAccount(name, opts...)
+}
+
+// override set not empty values of o
+func (p *Profile) override(o Profile) {
+	if o.APIKey != "" {
+		p.APIKey = o.APIKey
+	}
+	if o.Username != "" {
+		p.Username = o.Username
+	}
+	if o.Passkey != "" {
+		p.Passkey = o.Passkey
+	}
+}
+
+func newAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 || len(salt) == 0 {
+		return nil, ErrWrongPassphrase
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFile write data to temporary file and rename it to path
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, ".credentials-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,191 @@
+package credstore
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPasskey = "0123456789abcdef0123456789abcdef01234567"
+
+// clearEnv unset environment variables of credentials for test
+func clearEnv(t *testing.T) {
+	for _, k := range []string{EnvAPIKey, EnvUsername, EnvPasskey, EnvPassphrase, EnvProfile} {
+		t.Setenv(k, "")
+	}
+}
+
+// savedStore return path of saved store with profiles work and home
+func savedStore(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cinemate", "credentials")
+	s, err := Open(path, "secret phrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Set("work", Profile{APIKey: "APIKEY", Username: "user", Passkey: testPasskey})
+	s.Set("home", Profile{APIKey: "HOMEKEY"})
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := savedStore(t)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"APIKEY", "HOMEKEY", testPasskey, "user"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("file contains %s in plain text", secret)
+		}
+	}
+	s, err := Open(path, "secret phrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := s.Names(); !reflect.DeepEqual(names, []string{"home", "work"}) {
+		t.Errorf("Names() = %v", names)
+	}
+	p, err := s.Get("work")
+	if err != nil || p != (Profile{APIKey: "APIKEY", Username: "user", Passkey: testPasskey}) {
+		t.Errorf("Get(work) = %+v, %v", p, err)
+	}
+	s.Delete("home")
+	if _, err := s.Get("home"); !errors.Is(err, ErrNoProfile) {
+		t.Errorf("Get(home) after Delete err = %v, want ErrNoProfile", err)
+	}
+}
+
+func TestOpenAbsent(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "credentials"), "secret phrase")
+	if err != nil || len(s.Names()) != 0 {
+		t.Errorf("Open() of absent file = %v, %v, want empty store", s.Names(), err)
+	}
+}
+
+func TestOpenWrongPassphrase(t *testing.T) {
+	path := savedStore(t)
+	if _, err := Open(path, "other phrase"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Open() err = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestOpenCorrupted(t *testing.T) {
+	path := savedStore(t)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		change func(f *file)
+	}{
+		{"huge iterations", func(f *file) { f.Iterations = 1 << 40 }},
+		{"zero iterations", func(f *file) { f.Iterations = 0 }},
+		{"few iterations", func(f *file) { f.Iterations = 1 }},
+		{"version", func(f *file) { f.Version = 2 }},
+		{"data", func(f *file) { f.Data[0] ^= 1 }},
+		{"nonce", func(f *file) { f.Nonce = f.Nonce[1:] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := f
+			c.Data = append([]byte(nil), f.Data...)
+			tt.change(&c)
+			data, _ := json.Marshal(c)
+			if err := ioutil.WriteFile(path, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			if _, err := Open(path, "secret phrase"); err == nil {
+				t.Error("Open() of corrupted file err = nil")
+			}
+			if d := time.Since(start); d > 10*time.Second {
+				t.Errorf("Open() of corrupted file took %v", d)
+			}
+		})
+	}
+}
+
+func TestSavePermissions(t *testing.T) {
+	path := savedStore(t)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("file mode = %v, want 0600", mode)
+	}
+	info, err = os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0700 {
+		t.Errorf("directory mode = %v, want 0700", mode)
+	}
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".credentials-*"))
+	if len(matches) != 0 {
+		t.Errorf("temporary files left: %v", matches)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	clearEnv(t)
+	s, err := Open(savedStore(t), "secret phrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load(""); !errors.Is(err, ErrNoProfile) {
+		t.Errorf("Load() of absent default profile err = %v, want ErrNoProfile", err)
+	}
+	t.Setenv(EnvProfile, "home")
+	if p, err := s.Load(""); err != nil || p.APIKey != "HOMEKEY" {
+		t.Errorf("Load() of CINEMATE_PROFILE = %+v, %v", p, err)
+	}
+	t.Setenv(EnvAPIKey, "ENVKEY")
+	p, err := s.Load("work")
+	if err != nil || p != (Profile{APIKey: "ENVKEY", Username: "user", Passkey: testPasskey}) {
+		t.Errorf("Load(work) with CINEMATE_APIKEY = %+v, %v", p, err)
+	}
+	if saved, _ := s.Get("work"); saved.APIKey != "APIKEY" {
+		t.Errorf("Get(work) = %+v, want saved value without override", saved)
+	}
+	if p, err := s.Load("absent"); err != nil || p.APIKey != "ENVKEY" {
+		t.Errorf("Load(absent) with CINEMATE_APIKEY = %+v, %v", p, err)
+	}
+}
+
+func TestInit(t *testing.T) {
+	clearEnv(t)
+	s, err := Open(savedStore(t), "secret phrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Init("work"); err != nil {
+		t.Errorf("Init(work) err = %v", err)
+	}
+	if _, err := s.InitAccount("home"); err == nil {
+		t.Error("InitAccount(home) without passkey err = nil")
+	}
+	acc, err := s.InitAccount("work")
+	if err != nil || acc.Passkey != testPasskey {
+		t.Errorf("InitAccount(work) = %+v, %v", acc, err)
+	}
+	s.Set("empty", Profile{Username: "user"})
+	if _, err := s.Init("empty"); err == nil {
+		t.Error("Init() of profile without api key err = nil")
+	}
+}
@@ -0,0 +1,108 @@
+package cinemate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DatePrecision точность даты, сайт может возвращать неполные даты,
+// например только год выхода фильма
+type DatePrecision int
+
+// Precision of Date
+const (
+	DatePrecisionNone DatePrecision = iota
+	DatePrecisionYear
+	DatePrecisionMonth
+	DatePrecisionDay
+	DatePrecisionTime
+)
+
+// Moscow часовой пояс сайта, используется для дат без указания пояса
+var Moscow = loadMoscow()
+
+func loadMoscow() *time.Location {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		return time.FixedZone("MSK", 3*60*60)
+	}
+	return loc
+}
+
+// dateLayouts formats of dates of api server by precision
+var dateLayouts = []struct {
+	layout    string
+	precision DatePrecision
+}{
+	{time.RFC3339Nano, DatePrecisionTime},
+	{"2006-01-02T15:04:05", DatePrecisionTime},
+	{"2006-01-02 15:04:05", DatePrecisionTime},
+	{"2006-01-02T15:04", DatePrecisionTime},
+	{"2006-01-02", DatePrecisionDay},
+	{"2006-01", DatePrecisionMonth},
+	{"2006", DatePrecisionYear},
+}
+
+// Date is ISO date from api server. Dates without timezone are in Moscow
+// timezone, missing month and day of partial dates are set to 1
+// Time      разобранная дата
+// Raw       исходная строка
+// Precision точность даты
+type Date struct {
+	Time      time.Time
+	Raw       string
+	Precision DatePrecision
+}
+
+// ParseDate parse ISO date from api server
+func ParseDate(s string) (Date, error) {
+	raw := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Date{Raw: raw}, nil
+	}
+	s = trimZeroParts(s)
+	for _, l := range dateLayouts {
+		t, err := time.ParseInLocation(l.layout, s, Moscow)
+		if err == nil {
+			return Date{Time: t, Raw: raw, Precision: l.precision}, nil
+		}
+	}
+	return Date{Raw: raw}, fmt.Errorf("cinemate: invalid date %q", raw)
+}
+
+// IsZero report whether date is empty
+func (d Date) IsZero() bool {
+	return d.Precision == DatePrecisionNone
+}
+
+func (d Date) String() string {
+	return d.Raw
+}
+
+// MarshalText return original string of date
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.Raw), nil
+}
+
+// UnmarshalText parse date from xml element or json string. Unknown format
+// is not error, so one odd date does not fail whole response: Date keep Raw
+// with DatePrecisionNone
+func (d *Date) UnmarshalText(text []byte) error {
+	date, _ := ParseDate(string(text))
+	*d = date
+	return nil
+}
+
+// trimZeroParts cut zero month and day of partial dates: "2010-00-00" is
+// year, "2010-05-00" is month
+func trimZeroParts(s string) string {
+	if len(s) == len("2006-01-02") && strings.HasSuffix(s, "-00") {
+		s = s[:len("2006-01")]
+	}
+	if len(s) == len("2006-01") && strings.HasSuffix(s, "-00") {
+		s = s[:len("2006")]
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package cinemate
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		raw       string
+		want      time.Time
+		precision DatePrecision
+		err       bool
+	}{
+		{"2011-04-14T12:00:00", time.Date(2011, 4, 14, 12, 0, 0, 0, Moscow), DatePrecisionTime, false},
+		{"2011-04-14T12:00:00+03:00", time.Date(2011, 4, 14, 9, 0, 0, 0, time.UTC), DatePrecisionTime, false},
+		{"2010-05-31", time.Date(2010, 5, 31, 0, 0, 0, 0, Moscow), DatePrecisionDay, false},
+		{"2010-05", time.Date(2010, 5, 1, 0, 0, 0, 0, Moscow), DatePrecisionMonth, false},
+		{"2010-05-00", time.Date(2010, 5, 1, 0, 0, 0, 0, Moscow), DatePrecisionMonth, false},
+		{"2010-00-00", time.Date(2010, 1, 1, 0, 0, 0, 0, Moscow), DatePrecisionYear, false},
+		{"2010", time.Date(2010, 1, 1, 0, 0, 0, 0, Moscow), DatePrecisionYear, false},
+		{"", time.Time{}, DatePrecisionNone, false},
+		{"скоро", time.Time{}, DatePrecisionNone, true},
+		{"2010-13-40", time.Time{}, DatePrecisionNone, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			d, err := ParseDate(tt.raw)
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseDate() error = %v", err)
+			}
+			if !d.Time.Equal(tt.want) || d.Precision != tt.precision || d.Raw != tt.raw {
+				t.Errorf("ParseDate() = %+v, want %v precision %d", d, tt.want, tt.precision)
+			}
+		})
+	}
+}
+
+func TestDateUnmarshalInvalid(t *testing.T) {
+	var resp APIResponse
+	body := `<response><movie><id>1</id><release_date_world>2010-00-00</release_date_world>` +
+		`<release_date_russia>скоро</release_date_russia></movie></response>`
+	if err := xml.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Movies) != 1 {
+		t.Fatalf("got %d movies, want 1", len(resp.Movies))
+	}
+	m := resp.Movies[0]
+	if m.ReleaseDateWorld.Precision != DatePrecisionYear || m.ReleaseDateWorld.Time.Year() != 2010 {
+		t.Errorf("release_date_world = %+v", m.ReleaseDateWorld)
+	}
+	if !m.ReleaseDateRussia.IsZero() || m.ReleaseDateRussia.Raw != "скоро" {
+		t.Errorf("release_date_russia = %+v", m.ReleaseDateRussia)
+	}
+	var item UpdateListItem
+	if err := json.Unmarshal([]byte(`{"date":"вчера","url":"u"}`), &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.URL != "u" || item.Date.Raw != "вчера" {
+		t.Errorf("item = %+v", item)
+	}
+}
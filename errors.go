@@ -0,0 +1,127 @@
+package cinemate
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors for use with errors.Is
+var (
+	// ErrNotFound объект не найден
+	ErrNotFound = errors.New("cinemate: not found")
+	// ErrInvalidAPIKey неверный ключ разработчика
+	ErrInvalidAPIKey = errors.New("cinemate: invalid apikey")
+	// ErrInvalidPasskey неверный PASSKEY пользователя
+	ErrInvalidPasskey = errors.New("cinemate: invalid passkey")
+	// ErrRateLimited превышено число запросов к серверу
+	ErrRateLimited = errors.New("cinemate: rate limited")
+	// ErrCacheMiss ответ отсутствует в кэше в режиме offline
+	ErrCacheMiss = errors.New("cinemate: cache miss")
+	// ErrAuthFailed неверный логин или пароль, либо сервер вернул некорректный PASSKEY
+	ErrAuthFailed = errors.New("cinemate: authentication failed")
+)
+
+// errMalformedPasskey passkey returned by account.auth is not 40 hex digits
+var errMalformedPasskey = errors.New("malformed passkey in response")
+
+// AuthError is error of Login, match ErrAuthFailed
+// Username логин пользователя
+// Err      ошибка сервера или errMalformedPasskey
+type AuthError struct {
+	Username string
+	Err      error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("cinemate: login as %q: %v", e.Username, e.Err)
+}
+
+// Unwrap return underlying error
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// Is report whether target is ErrAuthFailed
+func (e *AuthError) Is(target error) bool {
+	return target == ErrAuthFailed
+}
+
+// APIError is error document returned by api.cinemate.cc
+// Code    код ошибки
+// Message текст ошибки
+type APIError struct {
+	Code    int64
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("cinemate: api error %d: %s", e.Code, e.Message)
+	}
+	return "cinemate: api error: " + e.Message
+}
+
+// sentinelMessages error messages of api server for sentinel errors, in
+// lower case without final period
+var sentinelMessages = map[error][]string{
+	ErrNotFound:       {"not found", "movie not found", "person not found", "объект не найден", "фильм не найден", "персона не найдена"},
+	ErrInvalidAPIKey:  {"invalid apikey", "invalid api key", "неверный apikey", "неверный ключ api"},
+	ErrInvalidPasskey: {"invalid passkey", "неверный passkey"},
+	ErrRateLimited:    {"rate limit exceeded", "too many requests", "превышен лимит запросов"},
+	ErrAuthFailed:     {"invalid username or password", "invalid login or password", "неверный логин или пароль"},
+}
+
+// Is report whether APIError match one of sentinel errors by code of error
+// or exact message of server
+func (e *APIError) Is(target error) bool {
+	switch {
+	case target == ErrNotFound && e.Code == 404:
+		return true
+	case target == ErrRateLimited && e.Code == 429:
+		return true
+	}
+	msg := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(e.Message)), ".")
+	for _, m := range sentinelMessages[target] {
+		if msg == m {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialsRejected report whether error document of account.auth reject
+// username or password: status 401 or 403 or message of invalid credentials.
+// Rate limit and server errors are not rejection of credentials
+func credentialsRejected(e *APIError) bool {
+	if errors.Is(e, ErrRateLimited) {
+		return false
+	}
+	return e.Code == 401 || e.Code == 403 || errors.Is(e, ErrAuthFailed)
+}
+
+// parseAPIError return *APIError if body is error document of api server
+func parseAPIError(format Format, body []byte) *APIError {
+	var resp APIErrorResponse
+	if format == FormatJSON {
+		if err := json.Unmarshal(body, &resp); err != nil || resp.Error == "" {
+			return nil
+		}
+		return &APIError{Code: resp.Code, Message: strings.TrimSpace(resp.Error)}
+	}
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return nil
+	}
+	msg := strings.TrimSpace(resp.Error)
+	switch {
+	case resp.XMLName.Local == "error":
+		if msg == "" {
+			msg = strings.TrimSpace(resp.Text)
+		}
+	case msg == "":
+		return nil
+	}
+	return &APIError{Code: resp.Code, Message: msg}
+}
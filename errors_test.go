@@ -0,0 +1,77 @@
+package cinemate
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	sentinels := []error{ErrNotFound, ErrInvalidAPIKey, ErrInvalidPasskey, ErrRateLimited, ErrAuthFailed}
+	tests := []struct {
+		name string
+		err  *APIError
+		want error
+	}{
+		{"not found by code", &APIError{Code: 404, Message: "Movie not found"}, ErrNotFound},
+		{"not found by message", &APIError{Message: "Объект не найден."}, ErrNotFound},
+		{"invalid apikey", &APIError{Code: 403, Message: "Invalid apikey"}, ErrInvalidAPIKey},
+		{"invalid passkey", &APIError{Code: 403, Message: "Invalid passkey"}, ErrInvalidPasskey},
+		{"rate limited by code", &APIError{Code: 429, Message: "Slow down"}, ErrRateLimited},
+		{"rate limited by message", &APIError{Message: "Rate limit exceeded"}, ErrRateLimited},
+		{"auth failed by message", &APIError{Code: 401, Message: "Invalid username or password"}, ErrAuthFailed},
+		{"per_page limit", &APIError{Code: 400, Message: "per_page limit is 25"}, nil},
+		{"passkey mentioned", &APIError{Code: 400, Message: "passkey or apikey required"}, nil},
+		{"unknown", &APIError{Code: 500, Message: "Internal error"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, s := range sentinels {
+				if got := errors.Is(tt.err, s); got != (s == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", tt.err, s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCredentialsRejected(t *testing.T) {
+	tests := []struct {
+		err  *APIError
+		want bool
+	}{
+		{&APIError{Code: 401, Message: "Invalid username or password"}, true},
+		{&APIError{Code: 403, Message: "Forbidden"}, true},
+		{&APIError{Message: "Неверный логин или пароль."}, true},
+		{&APIError{Code: 403, Message: "Rate limit exceeded"}, false},
+		{&APIError{Code: 429, Message: "Slow down"}, false},
+		{&APIError{Code: 500, Message: "Internal error"}, false},
+	}
+	for _, tt := range tests {
+		if got := credentialsRejected(tt.err); got != tt.want {
+			t.Errorf("credentialsRejected(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestParseAPIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		body   string
+		want   *APIError
+	}{
+		{"xml", FormatXML, `<error><error>Invalid apikey</error><code>403</code></error>`, &APIError{Code: 403, Message: "Invalid apikey"}},
+		{"xml text", FormatXML, `<error>Movie not found</error>`, &APIError{Message: "Movie not found"}},
+		{"json", FormatJSON, `{"error":"Invalid passkey","code":403}`, &APIError{Code: 403, Message: "Invalid passkey"}},
+		{"xml response", FormatXML, `<response><movie><id>1</id></movie></response>`, nil},
+		{"json response", FormatJSON, `{"movie":[{"id":1}]}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseAPIError(tt.format, []byte(tt.body))
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("parseAPIError() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Package export write movies and persons of cinemate to CSV, JSON Lines
+// and XML. Every function stream records to io.Writer one by one.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/serbe/cinemate"
+)
+
+// ListSeparator разделитель значений списков (страны, жанры, актеры) в CSV
+const ListSeparator = "; "
+
+// movieColumns values of CSV columns of movie
+var movieColumns = map[string]func(cinemate.Movie) string{
+	"id":                  func(m cinemate.Movie) string { return formatInt(m.ID) },
+	"type":                func(m cinemate.Movie) string { return m.Type },
+	"title_russian":       func(m cinemate.Movie) string { return m.TitleRussian },
+	"title_original":      func(m cinemate.Movie) string { return m.TitleOriginal },
+	"title_english":       func(m cinemate.Movie) string { return m.TitleEnglish },
+	"year":                func(m cinemate.Movie) string { return formatInt(m.Year) },
+	"runtime":             func(m cinemate.Movie) string { return formatInt(m.Runtime) },
+	"country":             func(m cinemate.Movie) string { return strings.Join(m.Country.Name, ListSeparator) },
+	"genre":               func(m cinemate.Movie) string { return strings.Join(m.Genre.Name, ListSeparator) },
+	"director":            func(m cinemate.Movie) string { return m.Director.Name },
+	"cast":                func(m cinemate.Movie) string { return joinNames(m.Cast) },
+	"imdb_rating":         func(m cinemate.Movie) string { return formatRating(m.Imdb.Rating, m.Imdb.Votes) },
+	"imdb_votes":          func(m cinemate.Movie) string { return formatInt(m.Imdb.Votes) },
+	"kinopoisk_rating":    func(m cinemate.Movie) string { return formatRating(m.Kinopoisk.Rating, m.Kinopoisk.Votes) },
+	"kinopoisk_votes":     func(m cinemate.Movie) string { return formatInt(m.Kinopoisk.Votes) },
+	"release_date_world":  func(m cinemate.Movie) string { return m.ReleaseDateWorld.Raw },
+	"release_date_russia": func(m cinemate.Movie) string { return m.ReleaseDateRussia.Raw },
+	"description":         func(m cinemate.Movie) string { return m.Description },
+	"trailer":             func(m cinemate.Movie) string { return m.Trailer },
+	"poster":              func(m cinemate.Movie) string { return m.Poster.Big.URL },
+	"url":                 func(m cinemate.Movie) string { return m.URL },
+}
+
+// personColumns values of CSV columns of person
+var personColumns = map[string]func(cinemate.Person) string{
+	"id":              func(p cinemate.Person) string { return formatInt(p.ID) },
+	"name":            func(p cinemate.Person) string { return p.Name },
+	"name_original":   func(p cinemate.Person) string { return p.NameOriginal },
+	"photo":           func(p cinemate.Person) string { return p.Photo.Big.URL },
+	"url":             func(p cinemate.Person) string { return p.URL },
+	"director_movies": func(p cinemate.Person) string { return joinTitles(p.Movies.Director) },
+	"actor_movies":    func(p cinemate.Person) string { return joinTitles(p.Movies.Actor) },
+}
+
+// DefaultMovieColumns columns of movies CSV if columns are not set
+var DefaultMovieColumns = []string{
+	"id", "type", "title_russian", "title_original", "year", "runtime",
+	"country", "genre", "director", "cast",
+	"imdb_rating", "imdb_votes", "kinopoisk_rating", "kinopoisk_votes",
+	"release_date_world", "release_date_russia", "url",
+}
+
+// DefaultPersonColumns columns of persons CSV if columns are not set
+var DefaultPersonColumns = []string{"id", "name", "name_original", "url"}
+
+// MoviesCSV write movies as CSV with header. columns select and order
+// columns, DefaultMovieColumns if empty
+func MoviesCSV(w io.Writer, movies []cinemate.Movie, columns ...string) error {
+	if len(columns) == 0 {
+		columns = DefaultMovieColumns
+	}
+	fields, err := selectColumns(movieColumns, columns)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(fields))
+	for _, m := range movies {
+		for i, f := range fields {
+			record[i] = f(m)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// PersonsCSV write persons as CSV with header. columns select and order
+// columns, DefaultPersonColumns if empty
+func PersonsCSV(w io.Writer, persons []cinemate.Person, columns ...string) error {
+	if len(columns) == 0 {
+		columns = DefaultPersonColumns
+	}
+	fields, err := selectColumns(personColumns, columns)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(fields))
+	for _, p := range persons {
+		for i, f := range fields {
+			record[i] = f(p)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// MoviesJSONL write movies as newline-delimited JSON, one movie per line
+func MoviesJSONL(w io.Writer, movies []cinemate.Movie) error {
+	return writeJSONL(w, movies)
+}
+
+// PersonsJSONL write persons as newline-delimited JSON, one person per line
+func PersonsJSONL(w io.Writer, persons []cinemate.Person) error {
+	return writeJSONL(w, persons)
+}
+
+// MoviesXML write movies as xml document of api server
+// <response><movie>...</movie>...</response>
+func MoviesXML(w io.Writer, movies []cinemate.Movie) error {
+	return writeXML(w, "movie", movies)
+}
+
+// PersonsXML write persons as xml document of api server
+// <response><person>...</person>...</response>
+func PersonsXML(w io.Writer, persons []cinemate.Person) error {
+	return writeXML(w, "person", persons)
+}
+
+func writeJSONL[T any](w io.Writer, items []T) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeXML[T any](w io.Writer, name string, items []T) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	root := xml.StartElement{Name: xml.Name{Local: "response"}}
+	if err := enc.EncodeToken(root); err != nil {
+		return err
+	}
+	for _, item := range items {
+		err := enc.EncodeElement(item, xml.StartElement{Name: xml.Name{Local: name}})
+		if err != nil {
+			return err
+		}
+	}
+	if err := enc.EncodeToken(root.End()); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func selectColumns[T any](all map[string]func(T) string, columns []string) ([]func(T) string, error) {
+	fields := make([]func(T) string, len(columns))
+	for i, name := range columns {
+		f, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("export: unknown column %q", name)
+		}
+		fields[i] = f
+	}
+	return fields, nil
+}
+
+func joinNames(persons []cinemate.Person) string {
+	names := make([]string, len(persons))
+	for i, p := range persons {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ListSeparator)
+}
+
+func joinTitles(movies []cinemate.Movie) string {
+	titles := make([]string, len(movies))
+	for i, m := range movies {
+		titles[i] = m.TitleRussian
+	}
+	return strings.Join(titles, ListSeparator)
+}
+
+func formatInt(i int64) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.FormatInt(i, 10)
+}
+
+func formatRating(rating float64, votes int64) string {
+	if votes == 0 && rating == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(rating, 'f', -1, 64)
+}
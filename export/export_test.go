@@ -0,0 +1,128 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/serbe/cinemate"
+	"github.com/serbe/cinemate/cinematetest"
+)
+
+func TestMoviesCSV(t *testing.T) {
+	movies := cinematetest.Seed().Movies
+	var buf bytes.Buffer
+	if err := MoviesCSV(&buf, movies); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || !reflect.DeepEqual(records[0], DefaultMovieColumns) {
+		t.Fatalf("records = %q", records)
+	}
+	row := map[string]string{}
+	for i, name := range records[0] {
+		row[name] = records[1][i]
+	}
+	want := map[string]string{
+		"id":                  "68675",
+		"title_original":      "Henry's Crime",
+		"genre":               "Комедия; Криминал",
+		"director":            "Квентин Тарантино",
+		"cast":                "Джейк Джилленхол",
+		"imdb_rating":         "6",
+		"release_date_russia": "2011-04-14",
+	}
+	for k, v := range want {
+		if row[k] != v {
+			t.Errorf("%s = %q, want %q", k, row[k], v)
+		}
+	}
+}
+
+func TestMoviesCSVColumns(t *testing.T) {
+	movies := []cinemate.Movie{{ID: 1, TitleRussian: "Раз, два"}}
+	var buf bytes.Buffer
+	if err := MoviesCSV(&buf, movies, "title_russian", "id", "year"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "title_russian,id,year\n\"Раз, два\",1,\n"; buf.String() != want {
+		t.Errorf("csv = %q, want %q", buf.String(), want)
+	}
+	if err := MoviesCSV(&buf, movies, "id", "budget"); err == nil || !strings.Contains(err.Error(), "budget") {
+		t.Errorf("unknown column err = %v", err)
+	}
+}
+
+func TestPersonsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PersonsCSV(&buf, cinematetest.Seed().Persons, "id", "name"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "id,name\n3971,Квентин Тарантино\n68675,Джейк Джилленхол\n"; buf.String() != want {
+		t.Errorf("csv = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	data := cinematetest.Seed()
+	var movies, persons bytes.Buffer
+	if err := MoviesJSONL(&movies, data.Movies); err != nil {
+		t.Fatal(err)
+	}
+	if err := PersonsJSONL(&persons, data.Persons); err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, buf := range []*bytes.Buffer{&movies, &persons} {
+		sc := bufio.NewScanner(buf)
+		for sc.Scan() {
+			var v struct{ ID int64 }
+			if err := json.Unmarshal(sc.Bytes(), &v); err != nil {
+				t.Fatalf("line %s: %v", sc.Bytes(), err)
+			}
+			ids = append(ids, v.ID)
+		}
+	}
+	if want := []int64{68675, 2, 3971, 68675}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+	if strings.Contains(movies.String(), `&`) {
+		t.Errorf("html escaped json: %s", movies.String())
+	}
+}
+
+func TestXML(t *testing.T) {
+	data := cinematetest.Seed()
+	var buf bytes.Buffer
+	if err := MoviesXML(&buf, data.Movies); err != nil {
+		t.Fatal(err)
+	}
+	if err := PersonsXML(&buf, data.Persons); err != nil {
+		t.Fatal(err)
+	}
+	docs := strings.SplitAfter(buf.String(), "</response>\n")
+	if len(docs) != 3 || !strings.HasPrefix(docs[0], xml.Header) {
+		t.Fatalf("xml = %s", buf.String())
+	}
+	var movies, persons cinemate.APIResponse
+	if err := xml.Unmarshal([]byte(docs[0]), &movies); err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal([]byte(docs[1]), &persons); err != nil {
+		t.Fatal(err)
+	}
+	if len(movies.Movies) != 2 || movies.Movies[0].TitleOriginal != "Henry's Crime" || movies.Movies[1].ReleaseDateRussia.Raw != "1995-02-01" {
+		t.Errorf("movies = %+v", movies.Movies)
+	}
+	if len(persons.Persons) != 2 || persons.Persons[0].Name != "Квентин Тарантино" {
+		t.Errorf("persons = %+v", persons.Persons)
+	}
+}
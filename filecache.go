@@ -0,0 +1,133 @@
+package cinemate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// StaleCache is Cache able to return expired values. Client in offline mode
+// serve responses from StaleCache regardless of ttl
+type StaleCache interface {
+	Cache
+	// GetStale return value of key and time of fetch from server even if
+	// value is expired
+	GetStale(key string) (value []byte, fetched time.Time, ok bool)
+}
+
+// FileCache Cache stored in directory. Every entry is raw response body in
+// file <hash>.body and metadata with fetch time in file <hash>.json, so
+// cache survive restarts of program
+type FileCache struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// fileEntry metadata of FileCache entry
+// key     ключ записи
+// fetched время получения ответа от сервера
+// expires время устаревания записи
+type fileEntry struct {
+	Key     string    `json:"key"`
+	Fetched time.Time `json:"fetched"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewFileCache create FileCache in directory dir
+func NewFileCache(dir string) (*FileCache, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+// Get return value of key and true if value exists and not expired
+func (f *FileCache) Get(key string) ([]byte, bool) {
+	value, entry, ok := f.read(key)
+	if !ok || time.Now().After(entry.Expires) {
+		return nil, false
+	}
+	return value, true
+}
+
+// GetStale return value of key and time of fetch even if value is expired
+func (f *FileCache) GetStale(key string) ([]byte, time.Time, bool) {
+	value, entry, ok := f.read(key)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return value, entry.Fetched, true
+}
+
+// Set store value of key for ttl
+func (f *FileCache) Set(key string, value []byte, ttl time.Duration) {
+	now := time.Now()
+	meta, err := json.Marshal(fileEntry{Key: key, Fetched: now, Expires: now.Add(ttl)})
+	if err != nil {
+		return
+	}
+	base := f.path(key)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if writeFile(base+".body", value) != nil {
+		return
+	}
+	_ = writeFile(base+".json", meta)
+}
+
+// Delete remove entry of key
+func (f *FileCache) Delete(key string) {
+	base := f.path(key)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	os.Remove(base + ".json")
+	os.Remove(base + ".body")
+}
+
+func (f *FileCache) read(key string) ([]byte, fileEntry, bool) {
+	var entry fileEntry
+	base := f.path(key)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	meta, err := ioutil.ReadFile(base + ".json")
+	if err != nil {
+		return nil, entry, false
+	}
+	if json.Unmarshal(meta, &entry) != nil || entry.Key != key {
+		return nil, entry, false
+	}
+	value, err := ioutil.ReadFile(base + ".body")
+	if err != nil {
+		return nil, entry, false
+	}
+	return value, entry, true
+}
+
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:]))
+}
+
+// writeFile write data to temporary file and rename it to name, so readers
+// never see partial file
+func writeFile(name string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,65 @@
+package cinemate
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	f, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Set("fresh", []byte("1"), time.Hour)
+	f.Set("expired", []byte("2"), -time.Second)
+	restarted, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := restarted.Get("fresh"); !ok || string(v) != "1" {
+		t.Errorf("Get(fresh) after restart = %q, %v", v, ok)
+	}
+	if _, ok := restarted.Get("expired"); ok {
+		t.Error("Get(expired) returned expired value")
+	}
+	v, fetched, ok := restarted.GetStale("expired")
+	if !ok || string(v) != "2" || time.Since(fetched) > time.Minute {
+		t.Errorf("GetStale(expired) = %q, %v, %v", v, fetched, ok)
+	}
+	restarted.Delete("fresh")
+	if _, _, ok := restarted.GetStale("fresh"); ok {
+		t.Error("deleted entry returned")
+	}
+	if _, ok := restarted.Get("missing"); ok {
+		t.Error("missing entry returned")
+	}
+}
+
+func TestClientOffline(t *testing.T) {
+	srv, n := countingServer(t, `<response><movie><id>2</id></movie></response>`)
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	online := NewClient(WithBaseURL(srv.URL), WithRateLimiter(nil), WithCache(cache), WithCacheTTL("/movie", time.Nanosecond))
+	if _, err := online.GetMovie(2); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	offline := NewClient(WithBaseURL(srv.URL), WithRateLimiter(nil), WithCache(cache), WithOffline(true))
+	movie, err := offline.GetMovie(2)
+	if err != nil || movie.ID != 2 {
+		t.Errorf("offline GetMovie() of stale entry = %+v, %v", movie, err)
+	}
+	if _, err := offline.GetMovie(3); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("offline GetMovie() of missing entry err = %v, want ErrCacheMiss", err)
+	}
+	if *n != 1 {
+		t.Errorf("server got %d requests, want 1", *n)
+	}
+	if s := offline.CacheStats(); s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("CacheStats() = %+v, want 1 hit and 1 miss", s)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/serbe/cinemate
+
+go 1.24
@@ -0,0 +1,101 @@
+package cinemate
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// CacheStatus use of cache by request
+type CacheStatus string
+
+// Values of CacheStatus
+const (
+	// CacheNone кэш не используется для запроса
+	CacheNone CacheStatus = ""
+	// CacheHit ответ получен из кэша
+	CacheHit CacheStatus = "hit"
+	// CacheMiss ответ отсутствует в кэше
+	CacheMiss CacheStatus = "miss"
+)
+
+// RequestInfo request to api method
+// Endpoint метод api, например "/movie"
+// Params   параметры запроса, значения apikey, passkey и password скрыты
+type RequestInfo struct {
+	Endpoint string
+	Params   url.Values
+}
+
+// ResponseInfo result of request to api method, including retries and cache
+// Duration время выполнения запроса
+// Status   код ответа сервера последней попытки, 0 если ответ получен из кэша или не получен
+// Bytes    размер ответа
+// Cache    использование кэша
+// Err      ошибка запроса или nil
+type ResponseInfo struct {
+	RequestInfo
+	Duration time.Duration
+	Status   int
+	Bytes    int
+	Cache    CacheStatus
+	Err      error
+}
+
+// Hook observe requests of Client, for example for metrics and tracing.
+// Methods are called from goroutines of requests and must be safe for
+// concurrent use
+type Hook interface {
+	// OnRequest вызывается перед запросом
+	OnRequest(ctx context.Context, info RequestInfo)
+	// OnResponse вызывается после успешного запроса
+	OnResponse(ctx context.Context, info ResponseInfo)
+	// OnError вызывается после запроса, завершившегося ошибкой
+	OnError(ctx context.Context, info ResponseInfo)
+}
+
+// WithHook add Hook of requests, hooks are called in order of adding
+func WithHook(hook Hook) Option {
+	return func(c *Client) {
+		c.hooks = append(c.hooks, hook)
+	}
+}
+
+// callInfo collect result of one call of api method
+type callInfo struct {
+	start  time.Time
+	status int
+	bytes  int
+	cache  CacheStatus
+}
+
+func (c *Client) onRequest(ctx context.Context, path string, q url.Values) {
+	if len(c.hooks) == 0 {
+		return
+	}
+	info := RequestInfo{Endpoint: path, Params: redactValues(q)}
+	for _, h := range c.hooks {
+		h.OnRequest(ctx, info)
+	}
+}
+
+func (c *Client) onResponse(ctx context.Context, call *callInfo, path string, q url.Values, err error) {
+	if len(c.hooks) == 0 {
+		return
+	}
+	info := ResponseInfo{
+		RequestInfo: RequestInfo{Endpoint: path, Params: redactValues(q)},
+		Duration:    time.Since(call.start),
+		Status:      call.status,
+		Bytes:       call.bytes,
+		Cache:       call.cache,
+		Err:         err,
+	}
+	for _, h := range c.hooks {
+		if err != nil {
+			h.OnError(ctx, info)
+		} else {
+			h.OnResponse(ctx, info)
+		}
+	}
+}
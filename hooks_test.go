@@ -0,0 +1,89 @@
+package cinemate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// recordHook Hook recording calls into shared log
+type recordHook struct {
+	name string
+	mu   *sync.Mutex
+	log  *[]string
+	last ResponseInfo
+}
+
+func (h *recordHook) add(event string, info RequestInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.log = append(*h.log, fmt.Sprintf("%s %s %s apikey=%s", h.name, event, info.Endpoint, info.Params.Get("apikey")))
+}
+
+func (h *recordHook) OnRequest(ctx context.Context, info RequestInfo) { h.add("request", info) }
+
+func (h *recordHook) OnResponse(ctx context.Context, info ResponseInfo) {
+	h.last = info
+	h.add("response", info.RequestInfo)
+}
+
+func (h *recordHook) OnError(ctx context.Context, info ResponseInfo) {
+	h.last = info
+	h.add("error", info.RequestInfo)
+}
+
+func TestHooks(t *testing.T) {
+	srv, _ := countingServer(t, `<response><movie><id>2</id></movie></response>`)
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+	first := &recordHook{name: "first", mu: &mu, log: &log}
+	second := &recordHook{name: "second", mu: &mu, log: &log}
+	c := NewClient(WithBaseURL(srv.URL), WithRateLimiter(nil), WithAPIKey("SECRET"),
+		WithCache(NewMemoryCache(10)), WithHook(first), WithHook(second))
+	if _, err := c.GetMovie(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetMovie(2); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"first request /movie apikey=REDACTED",
+		"second request /movie apikey=REDACTED",
+		"first response /movie apikey=REDACTED",
+		"second response /movie apikey=REDACTED",
+		"first request /movie apikey=REDACTED",
+		"second request /movie apikey=REDACTED",
+		"first response /movie apikey=REDACTED",
+		"second response /movie apikey=REDACTED",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("hook calls = %q, want %q", log, want)
+	}
+	if first.last.Cache != CacheHit || first.last.Status != 0 || first.last.Bytes == 0 {
+		t.Errorf("cached response info = %+v", first.last)
+	}
+}
+
+func TestHooksError(t *testing.T) {
+	srv, _ := countingServer(t, `<error><error>Movie not found</error><code>404</code></error>`)
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+	h := &recordHook{name: "hook", mu: &mu, log: &log}
+	c := NewClient(WithBaseURL(srv.URL), WithRateLimiter(nil), WithHook(h))
+	if _, err := c.GetMovie(1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetMovie() err = %v, want ErrNotFound", err)
+	}
+	if want := []string{"hook request /movie apikey=", "hook error /movie apikey="}; !reflect.DeepEqual(log, want) {
+		t.Errorf("hook calls = %q, want %q", log, want)
+	}
+	if !errors.Is(h.last.Err, ErrNotFound) || h.last.Status != 200 || h.last.Cache != CacheNone {
+		t.Errorf("error info = %+v", h.last)
+	}
+}
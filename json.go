@@ -0,0 +1,75 @@
+package cinemate
+
+import "encoding/json"
+
+// JSON document of api server nest persons and movies the same way as xml:
+// "director": {"person": {...}}, "cast": {"person": [...]},
+// "movies": {"director": {"movie": [...]}, "actor": {"movie": [...]}}
+
+type movieJSON Movie
+
+type movieDirectorJSON struct {
+	Person Person `json:"person"`
+}
+
+type movieCastJSON struct {
+	Person []Person `json:"person,omitempty"`
+}
+
+type movieListJSON struct {
+	Movie []Movie `json:"movie,omitempty"`
+}
+
+// MarshalJSON encode Movie as json document of api server
+func (m Movie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		movieJSON
+		Director movieDirectorJSON `json:"director"`
+		Cast     movieCastJSON     `json:"cast"`
+	}{
+		movieJSON: movieJSON(m),
+		Director:  movieDirectorJSON{Person: m.Director},
+		Cast:      movieCastJSON{Person: m.Cast},
+	})
+}
+
+// UnmarshalJSON decode Movie from json document of api server
+func (m *Movie) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		movieJSON
+		Director movieDirectorJSON `json:"director"`
+		Cast     movieCastJSON     `json:"cast"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*m = Movie(aux.movieJSON)
+	m.Director = aux.Director.Person
+	m.Cast = aux.Cast.Person
+	return nil
+}
+
+// MarshalJSON encode personMovies as json document of api server
+func (pm personMovies) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Director movieListJSON `json:"director"`
+		Actor    movieListJSON `json:"actor"`
+	}{
+		Director: movieListJSON{Movie: pm.Director},
+		Actor:    movieListJSON{Movie: pm.Actor},
+	})
+}
+
+// UnmarshalJSON decode personMovies from json document of api server
+func (pm *personMovies) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Director movieListJSON `json:"director"`
+		Actor    movieListJSON `json:"actor"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	pm.Director = aux.Director.Movie
+	pm.Actor = aux.Actor.Movie
+	return nil
+}
@@ -0,0 +1,53 @@
+package cinemate
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// redacted заменяет значения apikey, passkey и password в журнале и ошибках
+const redacted = "REDACTED"
+
+// WithLogger set logger of requests. Requests and responses are logged with
+// level Debug, failed requests with level Warn. Values of apikey, passkey
+// and password are redacted. Default nothing is logged
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		if logger == nil {
+			logger = slog.New(slog.DiscardHandler)
+		}
+		c.logger = logger
+	}
+}
+
+func (c *Client) logFailure(ctx context.Context, path string, status int, start time.Time, err error) {
+	c.logger.LogAttrs(ctx, slog.LevelWarn, "cinemate request failed",
+		slog.String("endpoint", path), slog.Int("status", status),
+		slog.Duration("duration", time.Since(start)), slog.String("error", err.Error()))
+}
+
+// redactValues return copy of q with redacted secrets
+func redactValues(q url.Values) url.Values {
+	rq := make(url.Values, len(q))
+	for k, v := range q {
+		rq[k] = v
+	}
+	for _, k := range secretParams {
+		if rq.Get(k) != "" {
+			rq.Set(k, redacted)
+		}
+	}
+	return rq
+}
+
+// redactURL return rawURL with redacted secrets
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return redacted
+	}
+	u.RawQuery = redactValues(u.Query()).Encode()
+	return u.String()
+}
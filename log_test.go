@@ -0,0 +1,77 @@
+package cinemate
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	logAPIKey   = "SECRETAPIKEY"
+	logPasskey  = "5ec5ec5ec5ec5ec5ec5ec5ec5ec5ec5ec5ec5ec5"
+	logPassword = "SECRETPASSWORD"
+)
+
+// checkNoSecrets fail test if s contains apikey, passkey or password
+func checkNoSecrets(t *testing.T, what, s string) {
+	t.Helper()
+	for _, secret := range []string{logAPIKey, logPasskey, logPassword} {
+		if strings.Contains(s, secret) {
+			t.Errorf("%s contains secret %s: %s", what, secret, s)
+		}
+	}
+}
+
+func TestLogRedaction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/account.auth":
+			w.Write([]byte(`<response><passkey>` + logPasskey + `</passkey></response>`))
+		case "/movie":
+			w.Write([]byte(`<response><movie><id>2</id></movie></response>`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`<error><error>Internal error</error><code>500</code></error>`))
+		}
+	}))
+	defer srv.Close()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := NewClient(WithBaseURL(srv.URL), WithRateLimiter(nil), WithLogger(logger),
+		WithAPIKey(logAPIKey), WithPasskey(logPasskey))
+	if _, err := c.GetMovie(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetAccountProfile(); err == nil {
+		t.Error("GetAccountProfile() err = nil, want server error")
+	} else {
+		checkNoSecrets(t, "error", err.Error())
+	}
+	if _, err := c.Login(context.Background(), "user", logPassword); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	for _, call := range []func() error{
+		func() error { _, err := c.GetMovie(3); return err },
+		func() error { _, err := c.GetAccountProfile(); return err },
+		func() error { _, err := c.Login(context.Background(), "user", logPassword); return err },
+	} {
+		err := call()
+		if err == nil {
+			t.Fatal("err = nil, want transport error")
+		}
+		checkNoSecrets(t, "transport error", err.Error())
+	}
+	out := buf.String()
+	if !strings.Contains(out, "apikey="+redacted) || !strings.Contains(out, "passkey="+redacted) {
+		t.Errorf("log has not redacted secrets: %s", out)
+	}
+	if !strings.Contains(out, "level=WARN") {
+		t.Errorf("log has not failed requests: %s", out)
+	}
+	checkNoSecrets(t, "log", out)
+}
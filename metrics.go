@@ -0,0 +1,141 @@
+package cinemate
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets upper bounds of latency histogram buckets of Metrics
+var DefaultLatencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Metrics is Hook collecting counters and latency histograms by endpoint
+type Metrics struct {
+	mu        sync.Mutex
+	buckets   []time.Duration
+	endpoints map[string]*EndpointMetrics
+}
+
+// EndpointMetrics counters and latency histogram of one api method
+// Requests    число запросов
+// Errors      число запросов, завершившихся ошибкой
+// CacheHits   число ответов из кэша
+// CacheMisses число ответов, отсутствовавших в кэше
+// Bytes       общий размер ответов
+// Status      число ответов по коду ответа сервера
+// Latency     гистограмма времени выполнения запросов
+type EndpointMetrics struct {
+	Requests    int64
+	Errors      int64
+	CacheHits   int64
+	CacheMisses int64
+	Bytes       int64
+	Status      map[int]int64
+	Latency     Histogram
+}
+
+// Histogram latency histogram. Counts[i] is number of requests with latency
+// not more than Buckets[i], last element of Counts is number of requests
+// slower than all buckets
+type Histogram struct {
+	Buckets []time.Duration
+	Counts  []int64
+	Sum     time.Duration
+	Count   int64
+}
+
+// NewMetrics create Metrics with latency buckets, DefaultLatencyBuckets if
+// empty
+func NewMetrics(buckets ...time.Duration) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := append([]time.Duration(nil), buckets...)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	return &Metrics{
+		buckets:   b,
+		endpoints: make(map[string]*EndpointMetrics),
+	}
+}
+
+// OnRequest implement Hook
+func (m *Metrics) OnRequest(ctx context.Context, info RequestInfo) {}
+
+// OnResponse implement Hook
+func (m *Metrics) OnResponse(ctx context.Context, info ResponseInfo) {
+	m.observe(info)
+}
+
+// OnError implement Hook
+func (m *Metrics) OnError(ctx context.Context, info ResponseInfo) {
+	m.observe(info)
+}
+
+// Snapshot return copy of metrics by endpoint
+func (m *Metrics) Snapshot() map[string]EndpointMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make(map[string]EndpointMetrics, len(m.endpoints))
+	for name, e := range m.endpoints {
+		c := *e
+		c.Status = make(map[int]int64, len(e.Status))
+		for k, v := range e.Status {
+			c.Status[k] = v
+		}
+		c.Latency.Counts = append([]int64(nil), e.Latency.Counts...)
+		snapshot[name] = c
+	}
+	return snapshot
+}
+
+// Reset clear all metrics
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.endpoints = make(map[string]*EndpointMetrics)
+}
+
+func (m *Metrics) observe(info ResponseInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.endpoints[info.Endpoint]
+	if !ok {
+		e = &EndpointMetrics{
+			Status: make(map[int]int64),
+			Latency: Histogram{
+				Buckets: m.buckets,
+				Counts:  make([]int64, len(m.buckets)+1),
+			},
+		}
+		m.endpoints[info.Endpoint] = e
+	}
+	e.Requests++
+	if info.Err != nil {
+		e.Errors++
+	}
+	switch info.Cache {
+	case CacheHit:
+		e.CacheHits++
+	case CacheMiss:
+		e.CacheMisses++
+	}
+	if info.Status != 0 {
+		e.Status[info.Status]++
+	}
+	e.Bytes += int64(info.Bytes)
+	i := sort.Search(len(m.buckets), func(i int) bool { return info.Duration <= m.buckets[i] })
+	e.Latency.Counts[i]++
+	e.Latency.Sum += info.Duration
+	e.Latency.Count++
+}
@@ -0,0 +1,51 @@
+package cinemate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(time.Second, 10*time.Millisecond)
+	ctx := context.Background()
+	info := func(d time.Duration, status int, cache CacheStatus, err error) ResponseInfo {
+		return ResponseInfo{RequestInfo: RequestInfo{Endpoint: "/movie"}, Duration: d, Status: status, Bytes: 10, Cache: cache, Err: err}
+	}
+	m.OnResponse(ctx, info(5*time.Millisecond, 200, CacheMiss, nil))
+	m.OnResponse(ctx, info(10*time.Millisecond, 0, CacheHit, nil))
+	m.OnError(ctx, info(500*time.Millisecond, 404, CacheNone, ErrNotFound))
+	m.OnError(ctx, info(2*time.Second, 0, CacheNone, errors.New("timeout")))
+	m.OnResponse(ctx, ResponseInfo{RequestInfo: RequestInfo{Endpoint: "/person"}, Status: 200})
+	s := m.Snapshot()
+	e := s["/movie"]
+	if e.Requests != 4 || e.Errors != 2 || e.CacheHits != 1 || e.CacheMisses != 1 || e.Bytes != 40 {
+		t.Errorf("counters = %+v", e)
+	}
+	if e.Status[200] != 1 || e.Status[404] != 1 || len(e.Status) != 2 {
+		t.Errorf("status = %v", e.Status)
+	}
+	h := e.Latency
+	if len(h.Buckets) != 2 || h.Buckets[0] != 10*time.Millisecond {
+		t.Errorf("buckets = %v, want sorted", h.Buckets)
+	}
+	if want := []int64{2, 1, 1}; len(h.Counts) != 3 || h.Counts[0] != want[0] || h.Counts[1] != want[1] || h.Counts[2] != want[2] {
+		t.Errorf("counts = %v, want %v", h.Counts, want)
+	}
+	if h.Count != 4 || h.Sum != 2515*time.Millisecond {
+		t.Errorf("count %d, sum %v", h.Count, h.Sum)
+	}
+	if s["/person"].Requests != 1 {
+		t.Errorf("/person = %+v", s["/person"])
+	}
+	e.Status[500] = 1
+	e.Latency.Counts[0] = 100
+	if s2 := m.Snapshot()["/movie"]; s2.Status[500] != 0 || s2.Latency.Counts[0] != 2 {
+		t.Error("snapshot shares state with metrics")
+	}
+	m.Reset()
+	if len(m.Snapshot()) != 0 {
+		t.Error("Reset() did not clear metrics")
+	}
+}
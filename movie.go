@@ -1,11 +1,9 @@
 package cinemate
 
 import (
-	"encoding/xml"
-	"fmt"
+	"context"
 	"net/url"
 	"strconv"
-	"time"
 )
 
 // GetMovie Информация о фильме
@@ -13,27 +11,25 @@ import (
 // apikey ключ разработчика
 // id     ID фильма
 // format необязательный параметр формата возвращаемых сервером данных: xml (по умолчанию) или json
-func (api *API) GetMovie(id int64) (movie Movie, err error) {
-	time.Sleep(1 * time.Second)
+func (c *Client) GetMovie(id int64) (movie Movie, err error) {
+	return c.GetMovieContext(context.Background(), id)
+}
+
+// GetMovieContext is GetMovie with context
+func (c *Client) GetMovieContext(ctx context.Context, id int64) (movie Movie, err error) {
 	var result APIResponse
-	var u url.URL
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/movie"
-	q := u.Query()
-	q.Set("apikey", api.apikey)
+	q := url.Values{}
+	q.Set("apikey", c.apikey)
 	q.Set("id", strconv.FormatInt(id, 10))
-	q.Set("format", "xml")
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
+	err = c.get(ctx, "/movie", q, &result)
 	if err != nil {
 		return
 	}
-	err = xml.Unmarshal(xmlBody, &result)
-	movie = result.Movies[0]
-	if movie.ID == 0 {
-		err = fmt.Errorf("Movie not found")
+	if len(result.Movies) == 0 || result.Movies[0].ID == 0 {
+		err = ErrNotFound
+		return
 	}
+	movie = result.Movies[0]
 	return
 }
 
@@ -51,61 +47,27 @@ func (api *API) GetMovie(id int64) (movie Movie, err error) {
 // from, to       значения среза параметра order_by в формате даты ДД.ММ.ГГГГ. Включительно.
 // page, per_page страница и количество записей в выборке. По умолчанию 0 и 10 соответственно. per_page не может быть более 25.
 // format         необязательный параметр формата возвращаемых сервером данных: xml (по умолчанию) или json
-func (api *API) GetMovieList(ccr CCRequest) (movies []Movie, err error) {
-	time.Sleep(1 * time.Second)
+func (c *Client) GetMovieList(ccr CCRequest) (movies []Movie, err error) {
+	return c.GetMovieListContext(context.Background(), ccr)
+}
+
+// GetMovieListContext is GetMovieList with context
+func (c *Client) GetMovieListContext(ctx context.Context, ccr CCRequest) (movies []Movie, err error) {
 	var result APIResponse
-	var u url.URL
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/movie.list"
-	q := u.Query()
-	q.Set("apikey", api.apikey)
-	if ccr.Type != "" {
-		q.Set("type", ccr.Type)
-	}
-	if ccr.State != "" {
-		q.Set("state", ccr.State)
-	}
-	if ccr.Mode != "" {
-		q.Set("mode", ccr.Mode)
-	}
-	if ccr.Year != 0 {
-		q.Set("year", strconv.FormatInt(ccr.Year, 10))
-	}
-	if ccr.Genre != "" {
-		q.Set("genre", ccr.Genre)
-	}
-	if ccr.Country != "" {
-		q.Set("country", ccr.Country)
-	}
-	if ccr.OrderBy != "" {
-		q.Set("order_by", ccr.OrderBy)
-	}
-	if ccr.Order != "" {
-		q.Set("order", ccr.Order)
-	}
-	if ccr.From != "" {
-		q.Set("from", ccr.From)
-	}
-	if ccr.To != "" {
-		q.Set("to", ccr.To)
-	}
-	if ccr.Page != 0 {
-		q.Set("page", strconv.FormatInt(ccr.Page, 10))
-	}
-	if ccr.PerPage != 0 {
-		q.Set("per_page", strconv.FormatInt(ccr.PerPage, 10))
+	q, err := ccr.Query()
+	if err != nil {
+		return
 	}
-	q.Set("format", "xml")
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
+	q.Set("apikey", c.apikey)
+	err = c.get(ctx, "/movie.list", q, &result)
 	if err != nil {
 		return
 	}
-	err = xml.Unmarshal(xmlBody, &result)
-	movies = result.Movies
-	if movies[0].ID == 0 {
-		err = fmt.Errorf("Movies not found")
+	movies = make([]Movie, 0, len(result.Movies))
+	for _, item := range result.Movies {
+		if item.ID != 0 {
+			movies = append(movies, item)
+		}
 	}
 	return
 }
@@ -115,26 +77,25 @@ func (api *API) GetMovieList(ccr CCRequest) (movies []Movie, err error) {
 // apikey ключ разработчика
 // term   искомая строка; поддерживается уточняющий поиск по году выхода фильма (год должен быть указан в конце искомой строки, например, "Пираты кариб 2003") и коррекцию ошибок при печати
 // format необязательный параметр формата возвращаемых сервером данных: xml (по умолчанию) или json
-func (api *API) GetMovieSearch(term string) (movies []Movie, err error) {
-	time.Sleep(1 * time.Second)
+func (c *Client) GetMovieSearch(term string) (movies []Movie, err error) {
+	return c.GetMovieSearchContext(context.Background(), term)
+}
+
+// GetMovieSearchContext is GetMovieSearch with context
+func (c *Client) GetMovieSearchContext(ctx context.Context, term string) (movies []Movie, err error) {
 	var result APIResponse
-	var u url.URL
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/movie.search"
-	q := u.Query()
-	q.Set("apikey", api.apikey)
+	q := url.Values{}
+	q.Set("apikey", c.apikey)
 	q.Set("term", term)
-	q.Set("format", "xml")
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
+	err = c.get(ctx, "/movie.search", q, &result)
 	if err != nil {
 		return
 	}
-	err = xml.Unmarshal(xmlBody, &result)
-	movies = result.Movies
-	if movies[0].ID == 0 {
-		err = fmt.Errorf("Movies not found")
+	movies = make([]Movie, 0, len(result.Movies))
+	for _, item := range result.Movies {
+		if item.ID != 0 {
+			movies = append(movies, item)
+		}
 	}
 	return
 }
@@ -0,0 +1,86 @@
+package cinemate_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/serbe/cinemate"
+	"github.com/serbe/cinemate/cinematetest"
+)
+
+// formats formats of recorded responses in testdata
+var formats = []cinemate.Format{cinemate.FormatXML, cinemate.FormatJSON}
+
+// replayClient return Client replaying recorded responses of testdata/name
+func replayClient(format cinemate.Format, name string) *cinemate.Client {
+	return cinematetest.ReplayClient(filepath.Join("testdata", name), cinemate.WithFormat(format))
+}
+
+// checkLookup check error of single object lookup from response name
+func checkLookup(t *testing.T, name string, err error) {
+	t.Helper()
+	var apiErr *cinemate.APIError
+	switch name {
+	case "empty", "error":
+		if !errors.Is(err, cinemate.ErrNotFound) {
+			t.Errorf("err = %v, want ErrNotFound", err)
+		}
+	case "malformed":
+		if err == nil || errors.Is(err, cinemate.ErrNotFound) || errors.As(err, &apiErr) {
+			t.Errorf("err = %v, want decode error", err)
+		}
+	}
+}
+
+// checkList check result of list or search from response name
+func checkList(t *testing.T, name string, n int, isNil bool, err error) {
+	t.Helper()
+	switch name {
+	case "search_empty", "empty":
+		if err != nil || isNil || n != 0 {
+			t.Errorf("got %d items, nil %v, err %v, want empty non-nil slice", n, isNil, err)
+		}
+	case "error":
+		if !errors.Is(err, cinemate.ErrNotFound) {
+			t.Errorf("err = %v, want ErrNotFound", err)
+		}
+	case "malformed":
+		if err == nil {
+			t.Error("err = nil, want decode error")
+		}
+	}
+}
+
+func TestGetMovieRecorded(t *testing.T) {
+	for _, format := range formats {
+		for _, name := range []string{"empty", "error", "malformed"} {
+			t.Run(string(format)+"/"+name, func(t *testing.T) {
+				_, err := replayClient(format, name).GetMovie(68675)
+				checkLookup(t, name, err)
+			})
+		}
+	}
+}
+
+func TestGetMovieSearchRecorded(t *testing.T) {
+	for _, format := range formats {
+		for _, name := range []string{"search_empty", "empty", "error", "malformed"} {
+			t.Run(string(format)+"/"+name, func(t *testing.T) {
+				movies, err := replayClient(format, name).GetMovieSearch("Пираты")
+				checkList(t, name, len(movies), movies == nil, err)
+			})
+		}
+	}
+}
+
+func TestGetMovieListRecorded(t *testing.T) {
+	for _, format := range formats {
+		for _, name := range []string{"search_empty", "empty", "error", "malformed"} {
+			t.Run(string(format)+"/"+name, func(t *testing.T) {
+				movies, err := replayClient(format, name).GetMovieList(cinemate.CCRequest{Year: 2010})
+				checkList(t, name, len(movies), movies == nil, err)
+			})
+		}
+	}
+}
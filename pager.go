@@ -0,0 +1,89 @@
+package cinemate
+
+import (
+	"context"
+	"iter"
+)
+
+// defaultPerPage число записей на странице movie.list по умолчанию
+const defaultPerPage = 10
+
+// MoviePager walk all pages of movie.list for CCRequest. Every page is
+// requested through Client, so rate limit of Client is respected
+type MoviePager struct {
+	c        *Client
+	ctx      context.Context
+	ccr      CCRequest
+	maxItems int
+	page     []Movie
+	movie    Movie
+	count    int
+	last     bool
+	err      error
+}
+
+// MovieListPager create MoviePager for CCRequest starting from ccr.Page.
+// maxItems limit number of movies, 0 - without limit
+func (c *Client) MovieListPager(ctx context.Context, ccr CCRequest, maxItems int) *MoviePager {
+	if ccr.PerPage == 0 {
+		ccr.PerPage = defaultPerPage
+	}
+	return &MoviePager{
+		c:        c,
+		ctx:      ctx,
+		ccr:      ccr,
+		maxItems: maxItems,
+	}
+}
+
+// Next advance pager to next movie, return false when movies are over or on
+// error
+func (p *MoviePager) Next() bool {
+	if p.err != nil || (p.maxItems > 0 && p.count >= p.maxItems) {
+		return false
+	}
+	for len(p.page) == 0 {
+		if p.last {
+			return false
+		}
+		movies, err := p.c.GetMovieListContext(p.ctx, p.ccr)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.page = movies
+		p.last = int64(len(movies)) < p.ccr.PerPage
+		p.ccr.Page++
+	}
+	p.movie = p.page[0]
+	p.page = p.page[1:]
+	p.count++
+	return true
+}
+
+// Movie return current movie of pager
+func (p *MoviePager) Movie() Movie {
+	return p.movie
+}
+
+// Err return error of last request or nil
+func (p *MoviePager) Err() error {
+	return p.err
+}
+
+// MovieListAll return iterator over movies of all pages of movie.list for
+// CCRequest. maxItems limit number of movies, 0 - without limit. On error
+// iterator yield zero Movie and error and stop
+func (c *Client) MovieListAll(ctx context.Context, ccr CCRequest, maxItems int) iter.Seq2[Movie, error] {
+	return func(yield func(Movie, error) bool) {
+		p := c.MovieListPager(ctx, ccr, maxItems)
+		for p.Next() {
+			if !yield(p.Movie(), nil) {
+				return
+			}
+		}
+		if err := p.Err(); err != nil {
+			yield(Movie{}, err)
+		}
+	}
+}
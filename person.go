@@ -1,11 +1,9 @@
 package cinemate
 
 import (
-	"encoding/xml"
-	"fmt"
+	"context"
 	"net/url"
 	"strconv"
-	"time"
 )
 
 // GetPerson Основная информация о персоне
@@ -13,27 +11,25 @@ import (
 // apikey ключ разработчика
 // id     ID персоны
 // format необязательный параметр формата возвращаемых сервером данных: xml (по умолчанию) или json
-func (api *API) GetPerson(id int64) (person Person, err error) {
-	time.Sleep(1 * time.Second)
+func (c *Client) GetPerson(id int64) (person Person, err error) {
+	return c.GetPersonContext(context.Background(), id)
+}
+
+// GetPersonContext is GetPerson with context
+func (c *Client) GetPersonContext(ctx context.Context, id int64) (person Person, err error) {
 	var result APIResponse
-	var u url.URL
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/person"
-	q := u.Query()
-	q.Set("apikey", api.apikey)
+	q := url.Values{}
+	q.Set("apikey", c.apikey)
 	q.Set("id", strconv.FormatInt(id, 10))
-	q.Set("format", "xml")
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
+	err = c.get(ctx, "/person", q, &result)
 	if err != nil {
 		return
 	}
-	err = xml.Unmarshal(xmlBody, &result)
-	person = result.Persons[0]
-	if person.ID == 0 {
-		err = fmt.Errorf("Person not found")
+	if len(result.Persons) == 0 || result.Persons[0].ID == 0 {
+		err = ErrNotFound
+		return
 	}
+	person = result.Persons[0]
 	return
 }
 
@@ -44,27 +40,25 @@ func (api *API) GetPerson(id int64) (person Person, err error) {
 // apikey ключ разработчика
 // id     ID персоны
 // format необязательный параметр формата возвращаемых сервером данных: xml (по умолчанию) или json
-func (api *API) GetPersonMovies(id int64) (persons []Person, err error) {
-	time.Sleep(1 * time.Second)
+func (c *Client) GetPersonMovies(id int64) (persons []Person, err error) {
+	return c.GetPersonMoviesContext(context.Background(), id)
+}
+
+// GetPersonMoviesContext is GetPersonMovies with context
+func (c *Client) GetPersonMoviesContext(ctx context.Context, id int64) (persons []Person, err error) {
 	var result APIResponse
-	var u url.URL
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/person.movies"
-	q := u.Query()
-	q.Set("apikey", api.apikey)
+	q := url.Values{}
+	q.Set("apikey", c.apikey)
 	q.Set("id", strconv.FormatInt(id, 10))
-	q.Set("format", "xml")
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
+	err = c.get(ctx, "/person.movies", q, &result)
 	if err != nil {
 		return
 	}
-	err = xml.Unmarshal(xmlBody, &result)
-	persons = result.Persons
-	if persons[0].ID == 0 {
-		err = fmt.Errorf("Persons not found")
+	if len(result.Persons) == 0 || result.Persons[0].ID == 0 {
+		err = ErrNotFound
+		return
 	}
+	persons = result.Persons
 	return
 }
 
@@ -73,26 +67,25 @@ func (api *API) GetPersonMovies(id int64) (persons []Person, err error) {
 // apikey ключ разработчика
 // term   искомая строка; поддерживается уточняющий поиск по году выхода фильма (год должен быть указан в конце искомой строки, например, "Пираты кариб 2003") и коррекцию ошибок при печати
 // format необязательный параметр формата возвращаемых сервером данных: xml (по умолчанию) или json
-func (api *API) GetPersonSearch(term string) (persons []Person, err error) {
-	time.Sleep(1 * time.Second)
+func (c *Client) GetPersonSearch(term string) (persons []Person, err error) {
+	return c.GetPersonSearchContext(context.Background(), term)
+}
+
+// GetPersonSearchContext is GetPersonSearch with context
+func (c *Client) GetPersonSearchContext(ctx context.Context, term string) (persons []Person, err error) {
 	var result APIResponse
-	var u url.URL
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/person.search"
-	q := u.Query()
-	q.Set("apikey", api.apikey)
+	q := url.Values{}
+	q.Set("apikey", c.apikey)
 	q.Set("term", term)
-	q.Set("format", "xml")
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
+	err = c.get(ctx, "/person.search", q, &result)
 	if err != nil {
 		return
 	}
-	err = xml.Unmarshal(xmlBody, &result)
-	persons = result.Persons
-	if persons[0].ID == 0 {
-		err = fmt.Errorf("Persons not found")
+	persons = make([]Person, 0, len(result.Persons))
+	for _, item := range result.Persons {
+		if item.ID != 0 {
+			persons = append(persons, item)
+		}
 	}
 	return
 }
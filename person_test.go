@@ -0,0 +1,36 @@
+package cinemate_test
+
+import "testing"
+
+func TestGetPersonRecorded(t *testing.T) {
+	for _, format := range formats {
+		for _, name := range []string{"empty", "error", "malformed"} {
+			t.Run(string(format)+"/"+name, func(t *testing.T) {
+				_, err := replayClient(format, name).GetPerson(3971)
+				checkLookup(t, name, err)
+			})
+		}
+	}
+}
+
+func TestGetPersonMoviesRecorded(t *testing.T) {
+	for _, format := range formats {
+		for _, name := range []string{"empty", "error", "malformed"} {
+			t.Run(string(format)+"/"+name, func(t *testing.T) {
+				_, err := replayClient(format, name).GetPersonMovies(3971)
+				checkLookup(t, name, err)
+			})
+		}
+	}
+}
+
+func TestGetPersonSearchRecorded(t *testing.T) {
+	for _, format := range formats {
+		for _, name := range []string{"search_empty", "empty", "error", "malformed"} {
+			t.Run(string(format)+"/"+name, func(t *testing.T) {
+				persons, err := replayClient(format, name).GetPersonSearch("гиленхол")
+				checkList(t, name, len(persons), persons == nil, err)
+			})
+		}
+	}
+}
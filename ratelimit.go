@@ -0,0 +1,98 @@
+package cinemate
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRate  = 1
+	defaultBurst = 1
+)
+
+// RateLimiter token bucket limiter of requests to api server. Every key
+// (apikey, passkey) has own bucket. RateLimiter is safe for concurrent use and
+// may be shared by many clients with WithRateLimiter
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter create RateLimiter
+// rate  число запросов в секунду
+// burst максимальное число запросов подряд без ожидания
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Wait block until request with key is allowed or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l.rate <= 0 {
+		return nil
+	}
+	delay := l.reserve(key)
+	if delay <= 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel(key)
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// reserve take token from bucket of key and return time to wait for it
+func (l *RateLimiter) reserve(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / l.rate * float64(time.Second))
+}
+
+// cancel return token of canceled reservation to bucket of key
+func (l *RateLimiter) cancel(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.buckets[key]; ok {
+		b.tokens++
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cinemate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := NewRateLimiter(10, 3)
+	for i := 0; i < 3; i++ {
+		if d := l.reserve("APIKEY"); d != 0 {
+			t.Fatalf("reserve %d = %v, want 0 within burst", i, d)
+		}
+	}
+	d := l.reserve("APIKEY")
+	if d < 90*time.Millisecond || d > 100*time.Millisecond {
+		t.Errorf("reserve after burst = %v, want about 100ms", d)
+	}
+	d = l.reserve("APIKEY")
+	if d < 190*time.Millisecond || d > 200*time.Millisecond {
+		t.Errorf("second reserve after burst = %v, want about 200ms", d)
+	}
+	if d := l.reserve("PASSKEY"); d != 0 {
+		t.Errorf("reserve of other key = %v, want 0", d)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	l := NewRateLimiter(100, 1)
+	l.reserve("APIKEY")
+	l.buckets["APIKEY"].last = time.Now().Add(-time.Second)
+	if d := l.reserve("APIKEY"); d != 0 {
+		t.Errorf("reserve after refill = %v, want 0", d)
+	}
+	if tokens := l.buckets["APIKEY"].tokens; tokens > 0.01 {
+		t.Errorf("tokens = %v, want bucket capped by burst", tokens)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	if err := l.Wait(context.Background(), "APIKEY"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, "APIKEY"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() err = %v, want DeadlineExceeded", err)
+	}
+	if tokens := l.buckets["APIKEY"].tokens; tokens < -0.01 {
+		t.Errorf("tokens = %v, want token of canceled wait returned", tokens)
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewRateLimiter(0, 1).Wait(canceled, "APIKEY"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() of canceled ctx err = %v, want Canceled", err)
+	}
+	if err := NewRateLimiter(0, 1).Wait(context.Background(), "APIKEY"); err != nil {
+		t.Errorf("Wait() without rate err = %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package cinemate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy policy of repeat of failed GET requests. Requests are repeated
+// on connection errors, status 429 and 5xx. Header Retry-After of response is
+// used as pause before next attempt if present
+// MaxAttempts общее число попыток, включая первую
+// BaseDelay   пауза перед второй попыткой, каждая следующая пауза удваивается
+// MaxDelay    максимальная пауза между попытками
+// OnAttempt   необязательная функция, вызываемая после каждой попытки
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	OnAttempt   func(RetryAttempt)
+}
+
+// RetryAttempt result of one attempt of request
+// Path    метод api
+// Attempt номер попытки, начиная с 1
+// Err     ошибка попытки или nil
+// Delay   пауза перед следующей попыткой, 0 если попыток больше не будет
+type RetryAttempt struct {
+	Path    string
+	Attempt int
+	Err     error
+	Delay   time.Duration
+}
+
+// DefaultRetryPolicy 3 attempts with pause from 1 second to 30 seconds
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   1 * time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
+// WithRetry set RetryPolicy of Client, default requests are not repeated
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = &policy
+	}
+}
+
+// retryError is error of attempt with pause requested by server
+type retryError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryError) Error() string { return e.err.Error() }
+
+func (e *retryError) Unwrap() error { return e.err }
+
+// withRetry call fn until it succeed, return not retryable error or
+// attempts of policy are over
+func (c *Client) withRetry(ctx context.Context, path string, fn func() ([]byte, error)) ([]byte, error) {
+	p := c.retry
+	if p == nil || p.MaxAttempts < 2 {
+		return unwrapRetry(fn())
+	}
+	for attempt := 1; ; attempt++ {
+		body, err := fn()
+		var delay time.Duration
+		if err != nil && attempt < p.MaxAttempts && retryable(ctx, err) {
+			delay = p.backoff(attempt, err)
+		}
+		if p.OnAttempt != nil {
+			p.OnAttempt(RetryAttempt{Path: path, Attempt: attempt, Err: err, Delay: delay})
+		}
+		if delay == 0 {
+			return unwrapRetry(body, err)
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// backoff return pause after attempt: Retry-After of server or exponential
+// pause with jitter
+func (p *RetryPolicy) backoff(attempt int, err error) time.Duration {
+	var re *retryError
+	if errors.As(err, &re) && re.retryAfter > 0 {
+		return re.retryAfter
+	}
+	d := p.BaseDelay
+	if d <= 0 {
+		d = DefaultRetryPolicy.BaseDelay
+	}
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryable report whether request failed with err may be repeated
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var re *retryError
+	if errors.As(err, &re) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func unwrapRetry(body []byte, err error) ([]byte, error) {
+	var re *retryError
+	if errors.As(err, &re) {
+		return body, re.err
+	}
+	return body, err
+}
+
+// parseRetryAfter return pause from Retry-After header in seconds or http date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(value); err == nil && sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package cinemate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		min, max time.Duration
+	}{
+		{"", 0, 0},
+		{"3", 3 * time.Second, 3 * time.Second},
+		{"0", 0, 0},
+		{"-1", 0, 0},
+		{"soon", 0, 0},
+		{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+	}
+	for _, tt := range tests {
+		if d := parseRetryAfter(tt.value); d < tt.min || d > tt.max {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v..%v", tt.value, d, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	tests := []struct {
+		attempt  int
+		err      error
+		min, max time.Duration
+	}{
+		{1, errors.New("eof"), 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, errors.New("eof"), 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, errors.New("eof"), 150 * time.Millisecond, 300 * time.Millisecond},
+		{10, errors.New("eof"), 150 * time.Millisecond, 300 * time.Millisecond},
+		{1, &retryError{err: errors.New("429"), retryAfter: 5 * time.Second}, 5 * time.Second, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if d := p.backoff(tt.attempt, tt.err); d < tt.min || d > tt.max {
+				t.Errorf("backoff(%d, %v) = %v, want %v..%v", tt.attempt, tt.err, d, tt.min, tt.max)
+				break
+			}
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"retry error", context.Background(), &retryError{err: errors.New("503")}, true},
+		{"rate limited", context.Background(), &APIError{Code: 429}, true},
+		{"server error", context.Background(), &APIError{Code: 502}, true},
+		{"not found", context.Background(), &APIError{Code: 404}, false},
+		{"unexpected eof", context.Background(), io.ErrUnexpectedEOF, true},
+		{"deadline", context.Background(), context.DeadlineExceeded, false},
+		{"canceled ctx", canceled, &retryError{err: errors.New("503")}, false},
+		{"other", context.Background(), errors.New("decode"), false},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.ctx, tt.err); got != tt.want {
+			t.Errorf("%s: retryable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	var n int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&n, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`<error><error>Unavailable</error><code>503</code></error>`))
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`<error><error>Rate limit exceeded</error><code>429</code></error>`))
+		default:
+			w.Write([]byte(`<response><movie><id>2</id></movie></response>`))
+		}
+	}))
+	defer srv.Close()
+	var attempts []RetryAttempt
+	c := NewClient(WithBaseURL(srv.URL), WithRateLimiter(nil), WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		OnAttempt:   func(a RetryAttempt) { attempts = append(attempts, a) },
+	}))
+	movie, err := c.GetMovie(2)
+	if err != nil || movie.ID != 2 {
+		t.Fatalf("GetMovie() = %+v, %v", movie, err)
+	}
+	if len(attempts) != 3 || !errors.Is(attempts[1].Err, ErrRateLimited) || attempts[2].Err != nil || attempts[2].Delay != 0 {
+		t.Errorf("attempts = %+v", attempts)
+	}
+	atomic.StoreInt32(&n, 0)
+	c = NewClient(WithBaseURL(srv.URL), WithRateLimiter(nil), WithRetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+	_, err = c.GetMovie(2)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 429 {
+		t.Errorf("GetMovie() err = %v, want APIError 429 after last attempt", err)
+	}
+}
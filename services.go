@@ -0,0 +1,50 @@
+package cinemate
+
+import "context"
+
+// MovieService methods of api for movies, implemented by *Client and *API
+type MovieService interface {
+	GetMovie(id int64) (Movie, error)
+	GetMovieContext(ctx context.Context, id int64) (Movie, error)
+	GetMovieList(ccr CCRequest) ([]Movie, error)
+	GetMovieListContext(ctx context.Context, ccr CCRequest) ([]Movie, error)
+	GetMovieSearch(term string) ([]Movie, error)
+	GetMovieSearchContext(ctx context.Context, term string) ([]Movie, error)
+}
+
+// PersonService methods of api for persons, implemented by *Client and *API
+type PersonService interface {
+	GetPerson(id int64) (Person, error)
+	GetPersonContext(ctx context.Context, id int64) (Person, error)
+	GetPersonMovies(id int64) ([]Person, error)
+	GetPersonMoviesContext(ctx context.Context, id int64) ([]Person, error)
+	GetPersonSearch(term string) ([]Person, error)
+	GetPersonSearchContext(ctx context.Context, term string) ([]Person, error)
+}
+
+// AccountService methods of api for user account, implemented by *Account,
+// *Client and *API
+type AccountService interface {
+	GetAccountProfile() (AccountProfile, error)
+	GetAccountProfileContext(ctx context.Context) (AccountProfile, error)
+	GetAccountUpdateList(newonly ...bool) (UpdateList, error)
+	GetAccountUpdateListContext(ctx context.Context, newonly ...bool) (UpdateList, error)
+	GetAccountWatchlist() (WatchList, error)
+	GetAccountWatchlistContext(ctx context.Context) (WatchList, error)
+}
+
+// StatsService methods of api for site statistics, implemented by *Client
+// and *API
+type StatsService interface {
+	GetStatsNew() (Stats, error)
+	GetStatsNewContext(ctx context.Context) (Stats, error)
+}
+
+var (
+	_ MovieService   = (*Client)(nil)
+	_ PersonService  = (*Client)(nil)
+	_ AccountService = (*Client)(nil)
+	_ StatsService   = (*Client)(nil)
+	_ MovieService   = (*API)(nil)
+	_ AccountService = (*Account)(nil)
+)
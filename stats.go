@@ -1,27 +1,30 @@
 package cinemate
 
 import (
-	"encoding/xml"
+	"context"
 	"net/url"
-	"time"
 )
 
 // GetStatsNew возвращает статистику сайта за последние сутки
 // Пример запроса: http://api.cinemate.cc/stats.new?format=xml
 // format	необязательный параметр формата возвращаемых сервером данных: xml (по умолчанию) или json
 func GetStatsNew() (stats Stats, err error) {
-	time.Sleep(1 * time.Second)
-	var u url.URL
-	u.Scheme = "http"
-	u.Host = "api.cinemate.cc"
-	u.Path = "/stats.new"
-	q := u.Query()
-	q.Set("format", "xml")
-	u.RawQuery = q.Encode()
-	xmlBody, err := getXML(u.String())
-	if err != nil {
-		return
-	}
-	err = xml.Unmarshal(xmlBody, &stats)
+	return defaultClient.GetStatsNewContext(context.Background())
+}
+
+// GetStatsNewContext is GetStatsNew with context
+func GetStatsNewContext(ctx context.Context) (stats Stats, err error) {
+	return defaultClient.GetStatsNewContext(ctx)
+}
+
+// GetStatsNew возвращает статистику сайта за последние сутки
+func (c *Client) GetStatsNew() (stats Stats, err error) {
+	return c.GetStatsNewContext(context.Background())
+}
+
+// GetStatsNewContext is GetStatsNew with context
+func (c *Client) GetStatsNewContext(ctx context.Context) (stats Stats, err error) {
+	q := url.Values{}
+	err = c.get(ctx, "/stats.new", q, &stats)
 	return
 }
@@ -0,0 +1,258 @@
+package cinemate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval интервал опроса ленты обновлений по умолчанию
+const DefaultWatchInterval = 5 * time.Minute
+
+// defaultWatchBackoff pause after failed polls of Watcher
+var defaultWatchBackoff = RetryPolicy{BaseDelay: 10 * time.Second, MaxDelay: 10 * time.Minute}
+
+// maxUndated number of remembered URLs of items without date
+const maxUndated = 1000
+
+// Cursor position of Watcher in update list: date of last delivered item
+// and URLs of delivered items with this date. Items with unparsed date are
+// remembered by URL in Undated
+// Date    дата последней доставленной записи
+// URLs    ссылки доставленных записей с датой Date
+// Undated ссылки доставленных записей без даты, не более maxUndated последних
+type Cursor struct {
+	Date    time.Time `json:"date"`
+	URLs    []string  `json:"urls,omitempty"`
+	Undated []string  `json:"undated,omitempty"`
+}
+
+// After report whether item is not delivered yet: item is later than
+// cursor or has the same date and unknown URL. Item without date is new
+// while its URL is unknown
+func (c Cursor) After(item UpdateListItem) bool {
+	t := item.Date.Time
+	if t.IsZero() {
+		return !slices.Contains(c.Undated, item.URL)
+	}
+	if !t.Equal(c.Date) {
+		return t.After(c.Date)
+	}
+	return !slices.Contains(c.URLs, item.URL)
+}
+
+// Advance return cursor moved to delivered item, items must be delivered in
+// order of date
+func (c Cursor) Advance(item UpdateListItem) Cursor {
+	t := item.Date.Time
+	switch {
+	case t.IsZero():
+		undated := append(append([]string(nil), c.Undated...), item.URL)
+		if len(undated) > maxUndated {
+			undated = undated[len(undated)-maxUndated:]
+		}
+		return Cursor{Date: c.Date, URLs: c.URLs, Undated: undated}
+	case t.After(c.Date):
+		return Cursor{Date: t, URLs: []string{item.URL}, Undated: c.Undated}
+	case t.Equal(c.Date):
+		return Cursor{Date: c.Date, URLs: append(append([]string(nil), c.URLs...), item.URL), Undated: c.Undated}
+	}
+	return c
+}
+
+// equal report whether cursors have the same position
+func (c Cursor) equal(o Cursor) bool {
+	return c.Date.Equal(o.Date) && slices.Equal(c.URLs, o.URLs) && slices.Equal(c.Undated, o.Undated)
+}
+
+// CursorStore keep Cursor of Watcher between runs
+type CursorStore interface {
+	// LoadCursor return saved cursor or zero Cursor if nothing is saved
+	LoadCursor() (Cursor, error)
+	// SaveCursor save cursor
+	SaveCursor(Cursor) error
+}
+
+// MemoryCursor is CursorStore in memory
+type MemoryCursor struct {
+	mu     sync.Mutex
+	cursor Cursor
+}
+
+// LoadCursor implement CursorStore
+func (m *MemoryCursor) LoadCursor() (Cursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cursor, nil
+}
+
+// SaveCursor implement CursorStore
+func (m *MemoryCursor) SaveCursor(c Cursor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cursor = c
+	return nil
+}
+
+// FileCursor is CursorStore in json file
+type FileCursor struct {
+	Path string
+}
+
+// LoadCursor implement CursorStore, absent file is zero Cursor
+func (f FileCursor) LoadCursor() (c Cursor, err error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &c)
+	return
+}
+
+// SaveCursor implement CursorStore, file is replaced atomically
+func (f FileCursor) SaveCursor(c Cursor) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return err
+	}
+	return writeFile(f.Path, data)
+}
+
+// Watcher poll update list of account and deliver new items once. Delivered
+// items are remembered by Cursor, so after restart with the same
+// CursorStore items are not repeated
+// Account  источник ленты обновлений, например *Account
+// Interval интервал опроса, по умолчанию DefaultWatchInterval
+// All      запрашивать всю ленту, а не только непрочитанные записи
+// Cursor   хранилище позиции, по умолчанию MemoryCursor
+// Backoff  паузы после ошибок опроса, MaxAttempts не используется
+// OnError  необязательная функция, вызываемая при ошибке опроса
+type Watcher struct {
+	Account  AccountService
+	Interval time.Duration
+	All      bool
+	Cursor   CursorStore
+	Backoff  *RetryPolicy
+	OnError  func(error)
+}
+
+// NewWatcher create Watcher of account with interval
+func NewWatcher(account AccountService, interval time.Duration) *Watcher {
+	return &Watcher{Account: account, Interval: interval}
+}
+
+// Run poll update list until ctx is done and call fn for every new item in
+// order of date. Error of fn stop delivery of this poll, item and later items
+// are delivered again on next poll. Run return ctx.Err() or error of
+// CursorStore
+func (w *Watcher) Run(ctx context.Context, fn func(context.Context, UpdateListItem) error) error {
+	store := w.Cursor
+	if store == nil {
+		store = &MemoryCursor{}
+	}
+	cursor, err := store.LoadCursor()
+	if err != nil {
+		return err
+	}
+	backoff := w.Backoff
+	if backoff == nil {
+		backoff = &defaultWatchBackoff
+	}
+	failures := 0
+	for {
+		var next Cursor
+		next, err = w.poll(ctx, cursor, fn)
+		if !next.equal(cursor) {
+			if serr := store.SaveCursor(next); serr != nil {
+				return serr
+			}
+			cursor = next
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		delay := w.interval()
+		if err != nil {
+			failures++
+			delay = backoff.backoff(failures, err)
+			if w.OnError != nil {
+				w.OnError(err)
+			}
+		} else {
+			failures = 0
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Watch poll update list in goroutine and send new items to returned
+// channel. Channel is closed when ctx is done or CursorStore fail
+func (w *Watcher) Watch(ctx context.Context) <-chan UpdateListItem {
+	ch := make(chan UpdateListItem)
+	go func() {
+		defer close(ch)
+		err := w.Run(ctx, func(ctx context.Context, item UpdateListItem) error {
+			select {
+			case ch <- item:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil && !errors.Is(err, ctx.Err()) && w.OnError != nil {
+			w.OnError(err)
+		}
+	}()
+	return ch
+}
+
+// poll request update list once and deliver items after cursor, return
+// cursor of delivered items
+func (w *Watcher) poll(ctx context.Context, cursor Cursor, fn func(context.Context, UpdateListItem) error) (Cursor, error) {
+	list, err := w.Account.GetAccountUpdateListContext(ctx, !w.All)
+	if err != nil {
+		return cursor, err
+	}
+	items := make([]UpdateListItem, 0, len(list.Items))
+	for _, item := range list.Items {
+		if cursor.After(item) {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Date.Time.Before(items[j].Date.Time)
+	})
+	for _, item := range items {
+		if err := fn(ctx, item); err != nil {
+			return cursor, err
+		}
+		cursor = cursor.Advance(item)
+	}
+	return cursor, nil
+}
+
+func (w *Watcher) interval() time.Duration {
+	if w.Interval <= 0 {
+		return DefaultWatchInterval
+	}
+	return w.Interval
+}
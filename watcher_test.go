@@ -0,0 +1,203 @@
+package cinemate_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/serbe/cinemate"
+	"github.com/serbe/cinemate/cinematefake"
+)
+
+// update return item of update list with url and date, invalid date keep Raw
+func update(url, date string) cinemate.UpdateListItem {
+	d, _ := cinemate.ParseDate(date)
+	return cinemate.UpdateListItem{URL: url, Date: d}
+}
+
+func TestCursor(t *testing.T) {
+	var c cinemate.Cursor
+	items := []cinemate.UpdateListItem{
+		update("a", "2011-04-14T10:00:00"),
+		update("b", "2011-04-14T10:00:00"),
+		update("c", "вчера"),
+		update("d", "2011-04-15T10:00:00"),
+	}
+	for _, item := range items {
+		if !c.After(item) {
+			t.Errorf("After(%s) before delivery = false", item.URL)
+		}
+		c = c.Advance(item)
+		if c.After(item) {
+			t.Errorf("After(%s) after delivery = true", item.URL)
+		}
+	}
+	tests := []struct {
+		item cinemate.UpdateListItem
+		want bool
+	}{
+		{update("a", "2011-04-14T10:00:00"), false},
+		{update("c", "вчера"), false},
+		{update("d", "2011-04-15T10:00:00"), false},
+		{update("e", "2011-04-15T10:00:00"), true},
+		{update("e", "2011-04-14T12:00:00"), false},
+		{update("e", "2011-04-16T10:00:00"), true},
+		{update("e", "вчера"), true},
+		{update("e", ""), true},
+	}
+	for _, tt := range tests {
+		if got := c.After(tt.item); got != tt.want {
+			t.Errorf("After(%s, %q) = %v, want %v", tt.item.URL, tt.item.Date.Raw, got, tt.want)
+		}
+	}
+	want := cinemate.Cursor{Date: items[3].Date.Time, URLs: []string{"d"}, Undated: []string{"c"}}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("cursor = %+v, want %+v", c, want)
+	}
+}
+
+func TestCursorUndatedLimit(t *testing.T) {
+	var c cinemate.Cursor
+	for i := 0; i < 1100; i++ {
+		c = c.Advance(update(fmt.Sprint("u", i), "вчера"))
+	}
+	if len(c.Undated) != 1000 || c.Undated[0] != "u100" || !c.After(update("u99", "вчера")) {
+		t.Errorf("Undated = %d urls from %s, want last 1000", len(c.Undated), c.Undated[0])
+	}
+}
+
+// collect run watcher until n items are delivered, stop it and return
+// URLs of items
+func collect(t *testing.T, w *cinemate.Watcher, n int) []string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var urls []string
+	ch := w.Watch(ctx)
+	for item := range ch {
+		urls = append(urls, item.URL)
+		if len(urls) == n {
+			break
+		}
+	}
+	cancel()
+	for item := range ch {
+		urls = append(urls, item.URL)
+	}
+	return urls
+}
+
+func TestWatcherUndated(t *testing.T) {
+	fake := cinematefake.New()
+	fake.AddUpdate(update("a", "2011-04-14T10:00:00"), update("b", "вчера"))
+	store := cinemate.FileCursor{Path: filepath.Join(t.TempDir(), "cursor.json")}
+	w := &cinemate.Watcher{Account: fake, Interval: time.Millisecond, Cursor: store}
+	if urls := collect(t, w, 2); !reflect.DeepEqual(urls, []string{"b", "a"}) {
+		t.Errorf("first run = %v, want [b a]", urls)
+	}
+	fake.AddUpdate(update("c", "сегодня"), update("d", "2011-04-14T11:00:00"))
+	if urls := collect(t, w, 2); !reflect.DeepEqual(urls, []string{"c", "d"}) {
+		t.Errorf("second run = %v, want [c d]", urls)
+	}
+	c, err := store.LoadCursor()
+	if err != nil || !reflect.DeepEqual(c.Undated, []string{"b", "c"}) || !reflect.DeepEqual(c.URLs, []string{"d"}) {
+		t.Errorf("LoadCursor() = %+v, %v", c, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	for item := range w.Watch(ctx) {
+		t.Errorf("item %s delivered again after restart", item.URL)
+	}
+}
+
+func TestWatcherBackoff(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		polls []time.Time
+	)
+	fake := cinematefake.New()
+	fake.UpdateListFunc = func(ctx context.Context, newonly bool) (cinemate.UpdateList, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		polls = append(polls, time.Now())
+		if len(polls) <= 4 {
+			return cinemate.UpdateList{}, cinemate.ErrRateLimited
+		}
+		return cinemate.UpdateList{Items: []cinemate.UpdateListItem{update("a", "2011-04-14T10:00:00")}}, nil
+	}
+	var errs int
+	w := &cinemate.Watcher{
+		Account:  fake,
+		Interval: time.Millisecond,
+		Backoff:  &cinemate.RetryPolicy{BaseDelay: 20 * time.Millisecond, MaxDelay: 80 * time.Millisecond},
+		OnError:  func(err error) { errs++ },
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := w.Run(ctx, func(ctx context.Context, item cinemate.UpdateListItem) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() err = %v, want Canceled", err)
+	}
+	if errs != 4 {
+		t.Errorf("OnError called %d times, want 4", errs)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(polls) != 5 {
+		t.Fatalf("polls = %d, want 5", len(polls))
+	}
+	for i, min := range []time.Duration{10, 20, 40, 40} {
+		if gap := polls[i+1].Sub(polls[i]); gap < min*time.Millisecond {
+			t.Errorf("pause after failure %d = %v, want at least %vms", i+1, gap, min)
+		}
+	}
+}
+
+func TestWatcherShutdown(t *testing.T) {
+	fake := cinematefake.New()
+	fake.AddUpdate(update("a", "2011-04-14T10:00:00"), update("b", "2011-04-14T11:00:00"))
+	w := &cinemate.Watcher{Account: fake, Interval: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx, func(ctx context.Context, item cinemate.UpdateListItem) error {
+			return nil
+		})
+	}()
+	for len(fake.CallsTo("GetAccountUpdateList")) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run() err = %v, want Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run() does not return after cancel")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	ch := w.Watch(ctx)
+	<-ch
+	cancel()
+	closed := make(chan struct{})
+	go func() {
+		for range ch {
+		}
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("channel of Watch is not closed after cancel")
+	}
+}
@@ -0,0 +1,317 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/serbe/cinemate"
+)
+
+// Defaults of Dispatcher
+const (
+	DefaultInterval  = time.Second
+	DefaultBaseDelay = 5 * time.Second
+	DefaultMaxDelay  = time.Hour
+	DefaultTimeout   = 10 * time.Second
+)
+
+// defaultClient http client of Dispatcher without Client
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// Endpoint webhook URL and secret of signature
+type Endpoint struct {
+	URL    string
+	Secret string
+}
+
+// DeliveryError failed attempt of delivery
+type DeliveryError struct {
+	Delivery Delivery
+	Err      error
+}
+
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("webhook: delivery %s to %s: %v", e.Delivery.ID, e.Delivery.URL, e.Err)
+}
+
+// Unwrap return underlying error
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// Dispatcher POST events from Outbox to endpoints. Failed deliveries are
+// repeated with exponential pause until endpoint return 2xx status
+// Endpoints   адреса webhook, каждое событие доставляется на все адреса
+// Outbox      хранилище доставок, по умолчанию MemoryOutbox
+// Client      http клиент, по умолчанию клиент с таймаутом DefaultTimeout
+// Interval    интервал проверки Outbox, по умолчанию DefaultInterval
+// BaseDelay   пауза после первой неудачной попытки, каждая следующая удваивается
+// MaxDelay    максимальная пауза между попытками
+// MaxAttempts число попыток, после которых доставка удаляется; 0 - без ограничения
+// OnError     необязательная функция, вызываемая при ошибках доставки и опроса
+type Dispatcher struct {
+	Endpoints   []Endpoint
+	Outbox      Outbox
+	Client      *http.Client
+	Interval    time.Duration
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxAttempts int
+	OnError     func(error)
+	once        sync.Once
+	wake        chan struct{}
+}
+
+// New create Dispatcher with outbox and endpoints
+func New(outbox Outbox, endpoints ...Endpoint) *Dispatcher {
+	return &Dispatcher{Outbox: outbox, Endpoints: endpoints}
+}
+
+// Enqueue save delivery of event to every endpoint in Outbox. Event is
+// delivered by Run
+func (d *Dispatcher) Enqueue(ev Event) error {
+	now := time.Now()
+	for _, ep := range d.Endpoints {
+		err := d.outbox().Put(Delivery{
+			ID:          deliveryID(ev.ID, ep.URL),
+			URL:         ep.URL,
+			Event:       ev,
+			NextAttempt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	d.notify()
+	return nil
+}
+
+// Run deliver events from Outbox until ctx is done, return ctx.Err()
+func (d *Dispatcher) Run(ctx context.Context) error {
+	d.init()
+	for {
+		if err := d.Flush(ctx); err != nil && ctx.Err() == nil {
+			d.onError(err)
+		}
+		t := time.NewTimer(d.interval())
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-d.wake:
+			t.Stop()
+		case <-t.C:
+		}
+	}
+}
+
+// Flush make one attempt of every delivery which time has come, return
+// error of Outbox. Errors of deliveries are passed to OnError
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	list, err := d.outbox().List()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, dl := range list {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if dl.NextAttempt.After(now) {
+			break
+		}
+		if err := d.attempt(ctx, dl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchUpdates enqueue events of new items of update list found by Watcher
+// until ctx is done. Cursor of Watcher move only after event is saved in
+// Outbox, so items are not lost
+func (d *Dispatcher) WatchUpdates(ctx context.Context, w *cinemate.Watcher) error {
+	return w.Run(ctx, func(ctx context.Context, item cinemate.UpdateListItem) error {
+		return d.Enqueue(UpdateEvent(item))
+	})
+}
+
+// WatchWatchlist enqueue events of new objects of watchlist of account of
+// Watcher until ctx is done. Watchlist is polled with Interval, Cursor and
+// Backoff of Watcher, All is not used
+func (d *Dispatcher) WatchWatchlist(ctx context.Context, w *cinemate.Watcher) error {
+	src := &watchlistSource{AccountService: w.Account}
+	wl := *w
+	wl.Account = src
+	return wl.Run(ctx, func(ctx context.Context, item cinemate.UpdateListItem) error {
+		return d.Enqueue(src.events[item.URL])
+	})
+}
+
+// attempt POST delivery once and update it in Outbox
+func (d *Dispatcher) attempt(ctx context.Context, dl Delivery) error {
+	err := d.post(ctx, dl)
+	if err == nil {
+		return d.outbox().Remove(dl.ID)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	d.onError(&DeliveryError{Delivery: dl, Err: err})
+	dl.Attempts++
+	dl.LastError = err.Error()
+	if d.MaxAttempts > 0 && dl.Attempts >= d.MaxAttempts {
+		return d.outbox().Remove(dl.ID)
+	}
+	dl.NextAttempt = time.Now().Add(d.backoff(dl.Attempts))
+	return d.outbox().Update(dl)
+}
+
+// post send event of delivery to its URL with signature
+func (d *Dispatcher) post(ctx context.Context, dl Delivery) error {
+	body, err := json.Marshal(dl.Event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", dl.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cinemate-webhook")
+	req.Header.Set(HeaderEvent, dl.Event.Type)
+	req.Header.Set(HeaderDelivery, dl.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, signaturePrefix+Sign(d.secret(dl.URL), ts, body))
+	client := d.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff return pause after attempts failed attempts with jitter
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	base, max := d.BaseDelay, d.MaxDelay
+	if base <= 0 {
+		base = DefaultBaseDelay
+	}
+	if max <= 0 {
+		max = DefaultMaxDelay
+	}
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// secret return secret of endpoint with url
+func (d *Dispatcher) secret(url string) string {
+	for _, ep := range d.Endpoints {
+		if ep.URL == url {
+			return ep.Secret
+		}
+	}
+	return ""
+}
+
+func (d *Dispatcher) init() {
+	d.once.Do(func() {
+		d.wake = make(chan struct{}, 1)
+		if d.Outbox == nil {
+			d.Outbox = &MemoryOutbox{}
+		}
+	})
+}
+
+// notify wake Run after Enqueue
+func (d *Dispatcher) notify() {
+	d.init()
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) outbox() Outbox {
+	d.init()
+	return d.Outbox
+}
+
+func (d *Dispatcher) interval() time.Duration {
+	if d.Interval <= 0 {
+		return DefaultInterval
+	}
+	return d.Interval
+}
+
+func (d *Dispatcher) onError(err error) {
+	if d.OnError != nil {
+		d.OnError(err)
+	}
+}
+
+// deliveryID return id of delivery of event to url
+func deliveryID(eventID, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return eventID + "-" + hex.EncodeToString(sum[:4])
+}
+
+// watchlistSource is AccountService with watchlist instead of update list,
+// so Watcher poll watchlist. Objects become update list items with date and
+// URL, events of last poll are kept by URL
+type watchlistSource struct {
+	cinemate.AccountService
+	events map[string]Event
+}
+
+// GetAccountUpdateListContext return objects of watchlist as items of update
+// list sorted by URL
+func (s *watchlistSource) GetAccountUpdateListContext(ctx context.Context, newonly ...bool) (list cinemate.UpdateList, err error) {
+	wl, err := s.GetAccountWatchlistContext(ctx)
+	if err != nil {
+		return
+	}
+	s.events = make(map[string]Event)
+	for kind, objs := range map[string][]cinemate.WatchListObject{
+		"movie":   wl.Movies,
+		"person":  wl.Persons,
+		"comment": wl.Comments,
+	} {
+		for _, obj := range objs {
+			s.events[obj.URL] = WatchlistEvent(kind, obj)
+			list.Items = append(list.Items, cinemate.UpdateListItem{Date: obj.Date, Description: obj.Description, URL: obj.URL})
+		}
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].URL < list.Items[j].URL
+	})
+	list.Count = int64(len(list.Items))
+	return
+}
@@ -0,0 +1,252 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/serbe/cinemate"
+	"github.com/serbe/cinemate/cinematefake"
+)
+
+// event return event of update list item with url
+func event(url string) Event {
+	d, _ := cinemate.ParseDate("2011-04-14T10:00:00")
+	return UpdateEvent(cinemate.UpdateListItem{Date: d, URL: url, Description: "Новый трейлер"})
+}
+
+// pending return deliveries in outbox of d
+func pending(t *testing.T, d *Dispatcher) []Delivery {
+	t.Helper()
+	list, err := d.outbox().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func TestDispatch(t *testing.T) {
+	tr := NewTestReceiver("secret")
+	defer tr.Close()
+	d := New(nil, tr.Endpoint("secret"))
+	ev := event("http://cinemate.cc/movie/2/")
+	if err := d.Enqueue(ev); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	events := tr.Events()
+	if len(events) != 1 || events[0].ID != ev.ID || events[0].Update == nil || events[0].Update.URL != ev.Update.URL {
+		t.Errorf("Events() = %+v, want %+v", events, ev)
+	}
+	if list := pending(t, d); len(list) != 0 {
+		t.Errorf("outbox after delivery = %+v", list)
+	}
+}
+
+func TestDispatchRetry(t *testing.T) {
+	tr := NewTestReceiver("secret")
+	defer tr.Close()
+	tr.FailNext(2)
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	d := New(nil, tr.Endpoint("secret"))
+	d.BaseDelay = 10 * time.Millisecond
+	d.OnError = func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+	if err := d.Enqueue(event("a")); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go d.Run(ctx)
+	if _, err := tr.Wait(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 2 {
+		t.Fatalf("OnError called %d times, want 2", len(errs))
+	}
+	var de *DeliveryError
+	if !errors.As(errs[1], &de) || de.Delivery.Attempts != 1 || de.Delivery.LastError == "" {
+		t.Errorf("second error = %#v, want DeliveryError after first attempt", errs[1])
+	}
+}
+
+func TestDispatchMaxAttempts(t *testing.T) {
+	tr := NewTestReceiver("secret")
+	defer tr.Close()
+	tr.FailNext(1)
+	d := New(nil, tr.Endpoint("secret"))
+	d.MaxAttempts = 1
+	if err := d.Enqueue(event("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if list := pending(t, d); len(list) != 0 {
+		t.Errorf("outbox after last attempt = %+v", list)
+	}
+}
+
+func TestDispatchDedup(t *testing.T) {
+	tr := NewTestReceiver("secret")
+	defer tr.Close()
+	d := New(nil, tr.Endpoint("secret"))
+	ev := event("a")
+	for i := 0; i < 2; i++ {
+		if err := d.Enqueue(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := pending(t, d)
+	if len(list) != 1 {
+		t.Fatalf("outbox after repeated Enqueue = %+v, want 1 delivery", list)
+	}
+	for i := 0; i < 2; i++ {
+		if err := d.post(context.Background(), list[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if events := tr.Events(); len(events) != 1 {
+		t.Errorf("repeated delivery received %d times, want 1", len(events))
+	}
+}
+
+func TestDeliveryHeaders(t *testing.T) {
+	var (
+		header http.Header
+		body   []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	d := New(nil, Endpoint{URL: srv.URL, Secret: "secret"}, Endpoint{URL: srv.URL + "/other"})
+	ev := event("a")
+	if err := d.Enqueue(ev); err != nil {
+		t.Fatal(err)
+	}
+	list := pending(t, d)
+	if len(list) != 2 || list[0].ID == list[1].ID {
+		t.Fatalf("deliveries = %+v, want 2 with different ID", list)
+	}
+	dl := list[0]
+	if dl.URL != srv.URL {
+		dl = list[1]
+	}
+	if err := d.post(context.Background(), dl); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get(HeaderDelivery); got != dl.ID || got != deliveryID(ev.ID, srv.URL) {
+		t.Errorf("%s = %q, want %q", HeaderDelivery, got, dl.ID)
+	}
+	if got := header.Get(HeaderEvent); got != EventUpdate {
+		t.Errorf("%s = %q, want %q", HeaderEvent, got, EventUpdate)
+	}
+	if err := Verify("secret", header, body, time.Minute); err != nil {
+		t.Errorf("Verify() err = %v", err)
+	}
+	if defaultClient.Timeout != DefaultTimeout {
+		t.Errorf("timeout of default client = %v", defaultClient.Timeout)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	signed := func(secret string, ts int64) http.Header {
+		h := http.Header{}
+		h.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+		h.Set(HeaderSignature, signaturePrefix+Sign(secret, ts, body))
+		return h
+	}
+	now := time.Now().Unix()
+	tests := []struct {
+		name      string
+		header    http.Header
+		body      []byte
+		tolerance time.Duration
+		want      error
+	}{
+		{"valid", signed("secret", now), body, time.Minute, nil},
+		{"wrong secret", signed("other", now), body, time.Minute, ErrSignature},
+		{"changed body", signed("secret", now), []byte(`{"id":"2"}`), time.Minute, ErrSignature},
+		{"stale", signed("secret", now-600), body, time.Minute, ErrTimestamp},
+		{"future", signed("secret", now+600), body, time.Minute, ErrTimestamp},
+		{"stale without tolerance", signed("secret", now-600), body, 0, nil},
+		{"no headers", http.Header{}, body, time.Minute, ErrSignature},
+	}
+	for _, tt := range tests {
+		if err := Verify("secret", tt.header, tt.body, tt.tolerance); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Verify() err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestWatchWatchlist(t *testing.T) {
+	date := func(s string) cinemate.Date {
+		d, _ := cinemate.ParseDate(s)
+		return d
+	}
+	fake := cinematefake.New()
+	fake.SetWatchlist(cinemate.WatchList{
+		Movies:  []cinemate.WatchListObject{{Date: date("2011-04-15T10:00:00"), Name: "Фильм", URL: "m"}},
+		Persons: []cinemate.WatchListObject{{Date: date("2011-04-14T10:00:00"), Name: "Актёр", URL: "p"}},
+	})
+	fake.SetError("GetAccountWatchlist", cinemate.ErrRateLimited)
+	var errs int
+	w := &cinemate.Watcher{
+		Account:  fake,
+		Interval: time.Millisecond,
+		Backoff:  &cinemate.RetryPolicy{BaseDelay: 20 * time.Millisecond},
+		OnError: func(err error) {
+			errs++
+			fake.SetError("GetAccountWatchlist", nil)
+		},
+	}
+	d := New(nil, Endpoint{URL: "http://example.com/hook"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- d.WatchWatchlist(ctx, w) }()
+	for len(pending(t, d)) < 2 && ctx.Err() == nil {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("WatchWatchlist() err = %v, want Canceled", err)
+	}
+	if errs != 1 {
+		t.Errorf("OnError called %d times, want 1", errs)
+	}
+	calls := fake.CallsTo("GetAccountWatchlist")
+	if len(calls) < 2 {
+		t.Fatalf("watchlist polled %d times", len(calls))
+	}
+	list := pending(t, d)
+	if len(list) != 2 {
+		t.Fatalf("outbox = %+v, want 2 deliveries", list)
+	}
+	for _, dl := range list {
+		ev := dl.Event
+		if ev.Type != EventWatchlist || ev.Watch == nil || (ev.Watch.Kind == "movie") != (ev.Watch.URL == "m") {
+			t.Errorf("event = %+v", ev)
+		}
+	}
+}
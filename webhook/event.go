@@ -0,0 +1,126 @@
+// Package webhook deliver events of cinemate account to webhook URLs. New
+// items of update list and new objects of watchlist become events, events
+// are kept in durable Outbox and POSTed as JSON with HMAC-SHA256 signature
+// until endpoint accept them.
+//
+//	d := webhook.New(&webhook.DirOutbox{Dir: "outbox"},
+//		webhook.Endpoint{URL: "https://example.com/hook", Secret: "secret"})
+//	go d.WatchUpdates(ctx, cinemate.NewWatcher(acc, 5*time.Minute))
+//	err := d.Run(ctx)
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/serbe/cinemate"
+)
+
+// Types of Event
+const (
+	// EventUpdate новая запись ленты обновлений
+	EventUpdate = "update"
+	// EventWatchlist новый объект списка слежения
+	EventWatchlist = "watchlist"
+)
+
+// Headers of webhook request
+const (
+	HeaderEvent     = "X-Cinemate-Event"
+	HeaderDelivery  = "X-Cinemate-Delivery"
+	HeaderTimestamp = "X-Cinemate-Timestamp"
+	HeaderSignature = "X-Cinemate-Signature"
+)
+
+// signaturePrefix prefix of signature in HeaderSignature
+const signaturePrefix = "sha256="
+
+var (
+	// ErrSignature подпись запроса отсутствует или неверна
+	ErrSignature = errors.New("webhook: invalid signature")
+	// ErrTimestamp время запроса вне допустимого интервала
+	ErrTimestamp = errors.New("webhook: timestamp out of tolerance")
+)
+
+// Event payload of webhook request
+// ID      идентификатор события, одинаковый для повторных доставок одного события
+// Type    тип события: EventUpdate или EventWatchlist
+// Created время создания события
+// Update  запись ленты обновлений для EventUpdate
+// Watch   объект слежения для EventWatchlist
+type Event struct {
+	ID      string                   `json:"id"`
+	Type    string                   `json:"type"`
+	Created time.Time                `json:"created"`
+	Update  *cinemate.UpdateListItem `json:"update,omitempty"`
+	Watch   *WatchObject             `json:"watch,omitempty"`
+}
+
+// WatchObject object of watchlist with kind: movie, person or comment
+type WatchObject struct {
+	Kind string `json:"kind"`
+	cinemate.WatchListObject
+}
+
+// UpdateEvent return event of item of update list
+func UpdateEvent(item cinemate.UpdateListItem) Event {
+	return Event{
+		ID:      eventID(EventUpdate, item.Date.Raw, item.URL),
+		Type:    EventUpdate,
+		Created: time.Now().UTC(),
+		Update:  &item,
+	}
+}
+
+// WatchlistEvent return event of object of watchlist with kind
+func WatchlistEvent(kind string, obj cinemate.WatchListObject) Event {
+	return Event{
+		ID:      eventID(EventWatchlist, kind, obj.Date.Raw, obj.URL),
+		Type:    EventWatchlist,
+		Created: time.Now().UTC(),
+		Watch:   &WatchObject{Kind: kind, WatchListObject: obj},
+	}
+}
+
+// eventID return id from hash of parts, so the same item give the same id
+func eventID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:12])
+}
+
+// Sign return signature of body sent at timestamp: hex HMAC-SHA256 of
+// "timestamp.body" with secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify check signature and timestamp headers of webhook request with
+// body. Timestamp must differ from now not more than tolerance, 0 disable
+// check of timestamp
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrSignature
+	}
+	sig := strings.TrimPrefix(header.Get(HeaderSignature), signaturePrefix)
+	if !hmac.Equal([]byte(sig), []byte(Sign(secret, ts, body))) {
+		return ErrSignature
+	}
+	if tolerance > 0 {
+		d := time.Since(time.Unix(ts, 0))
+		if d > tolerance || d < -tolerance {
+			return ErrTimestamp
+		}
+	}
+	return nil
+}
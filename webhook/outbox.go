@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Delivery pending delivery of event to endpoint
+// ID          идентификатор доставки
+// URL         адрес webhook
+// Event       событие
+// Attempts    число неудачных попыток
+// NextAttempt время следующей попытки
+// LastError   ошибка последней попытки
+type Delivery struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Event       Event     `json:"event"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Outbox keep pending deliveries until they succeed. Methods must be safe
+// for concurrent use
+type Outbox interface {
+	// Put add delivery, delivery with existing ID is not changed
+	Put(Delivery) error
+	// Update replace delivery
+	Update(Delivery) error
+	// Remove delete delivery
+	Remove(id string) error
+	// List return pending deliveries ordered by NextAttempt
+	List() ([]Delivery, error)
+}
+
+// MemoryOutbox is Outbox in memory, deliveries are lost on restart
+type MemoryOutbox struct {
+	mu         sync.Mutex
+	deliveries map[string]Delivery
+}
+
+// Put implement Outbox
+func (o *MemoryOutbox) Put(d Delivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.deliveries == nil {
+		o.deliveries = make(map[string]Delivery)
+	}
+	if _, ok := o.deliveries[d.ID]; !ok {
+		o.deliveries[d.ID] = d
+	}
+	return nil
+}
+
+// Update implement Outbox
+func (o *MemoryOutbox) Update(d Delivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.deliveries == nil {
+		o.deliveries = make(map[string]Delivery)
+	}
+	o.deliveries[d.ID] = d
+	return nil
+}
+
+// Remove implement Outbox
+func (o *MemoryOutbox) Remove(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.deliveries, id)
+	return nil
+}
+
+// List implement Outbox
+func (o *MemoryOutbox) List() ([]Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	list := make([]Delivery, 0, len(o.deliveries))
+	for _, d := range o.deliveries {
+		list = append(list, d)
+	}
+	sortDeliveries(list)
+	return list, nil
+}
+
+// DirOutbox is Outbox in directory, one json file per delivery. Files are
+// replaced atomically, so deliveries survive restart and crash
+type DirOutbox struct {
+	Dir string
+	mu  sync.Mutex
+}
+
+// Put implement Outbox
+func (o *DirOutbox) Put(d Delivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, err := os.Stat(o.path(d.ID)); err == nil {
+		return nil
+	}
+	return o.write(d)
+}
+
+// Update implement Outbox
+func (o *DirOutbox) Update(d Delivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.write(d)
+}
+
+// Remove implement Outbox
+func (o *DirOutbox) Remove(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	err := os.Remove(o.path(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// List implement Outbox
+func (o *DirOutbox) List() ([]Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	names, err := filepath.Glob(filepath.Join(o.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Delivery, 0, len(names))
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var d Delivery
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	sortDeliveries(list)
+	return list, nil
+}
+
+func (o *DirOutbox) path(id string) string {
+	return filepath.Join(o.Dir, strings.Replace(id, string(filepath.Separator), "_", -1)+".json")
+}
+
+// write save delivery to temporary file and rename it, o.mu must be locked
+func (o *DirOutbox) write(d Delivery) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.Dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(o.Dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), o.path(d.ID))
+}
+
+func sortDeliveries(list []Delivery) {
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].NextAttempt.Equal(list[j].NextAttempt) {
+			return list[i].NextAttempt.Before(list[j].NextAttempt)
+		}
+		return list[i].ID < list[j].ID
+	})
+}
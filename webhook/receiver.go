@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// DefaultTolerance допустимое расхождение времени подписи запроса
+const DefaultTolerance = 5 * time.Minute
+
+// maxBody максимальный размер тела запроса webhook
+const maxBody = 1 << 20
+
+// Receiver is http.Handler of webhook requests. Receiver verify signature,
+// skip repeated deliveries of the same event and pass events to OnEvent
+// Secret    секрет подписи
+// Tolerance допустимое расхождение времени подписи, по умолчанию DefaultTolerance
+// OnEvent   функция обработки события; ошибка возвращает отправителю статус 500 и доставка повторяется
+type Receiver struct {
+	Secret    string
+	Tolerance time.Duration
+	OnEvent   func(Event) error
+	mu        sync.Mutex
+	seen      map[string]bool
+}
+
+// ServeHTTP implement http.Handler
+func (rv *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tolerance := rv.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+	if err := Verify(rv.Secret, r.Header, body, tolerance); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var ev Event
+	if err := json.Unmarshal(body, &ev); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	if rv.seen[ev.ID] {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if rv.OnEvent != nil {
+		if err := rv.OnEvent(ev); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if rv.seen == nil {
+		rv.seen = make(map[string]bool)
+	}
+	rv.seen[ev.ID] = true
+	w.WriteHeader(http.StatusOK)
+}
+
+// TestReceiver is local webhook server for tests of delivery. It record
+// received events and may reject requests to check repeated delivery
+type TestReceiver struct {
+	*httptest.Server
+	mu     sync.Mutex
+	events []Event
+	fail   int
+	notify chan struct{}
+}
+
+// NewTestReceiver start TestReceiver with secret. Receiver must be closed
+// by Close
+func NewTestReceiver(secret string) *TestReceiver {
+	tr := &TestReceiver{notify: make(chan struct{}, 1)}
+	rv := &Receiver{Secret: secret, OnEvent: tr.record}
+	tr.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tr.mu.Lock()
+		if tr.fail > 0 {
+			tr.fail--
+			tr.mu.Unlock()
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		tr.mu.Unlock()
+		rv.ServeHTTP(w, r)
+	}))
+	return tr
+}
+
+// Endpoint return Endpoint of receiver with secret
+func (tr *TestReceiver) Endpoint(secret string) Endpoint {
+	return Endpoint{URL: tr.URL, Secret: secret}
+}
+
+// FailNext reject next n requests with status 503
+func (tr *TestReceiver) FailNext(n int) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.fail = n
+}
+
+// Events return received events in order
+func (tr *TestReceiver) Events() []Event {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return append([]Event(nil), tr.events...)
+}
+
+// Wait wait until n events are received or ctx is done
+func (tr *TestReceiver) Wait(ctx context.Context, n int) ([]Event, error) {
+	for {
+		if events := tr.Events(); len(events) >= n {
+			return events, nil
+		}
+		select {
+		case <-ctx.Done():
+			return tr.Events(), ctx.Err()
+		case <-tr.notify:
+		}
+	}
+}
+
+func (tr *TestReceiver) record(ev Event) error {
+	tr.mu.Lock()
+	tr.events = append(tr.events, ev)
+	tr.mu.Unlock()
+	select {
+	case tr.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}